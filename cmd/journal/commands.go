@@ -0,0 +1,533 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"journal-cli/internal/app"
+	"journal-cli/internal/domain"
+)
+
+// command describes a node in the subcommand tree. Leaf commands provide
+// setup, which registers the command's own flags and returns the action to
+// run once they have been parsed.
+type command struct {
+	name    string
+	args    string // argument synopsis shown in usage, e.g. "[date]"
+	summary string
+	help    string // optional longer description shown by `journal help <cmd>`
+	subs    []*command
+	setup   func(fs *flag.FlagSet) func(opts app.Options, args []string) error
+}
+
+func (c *command) sub(name string) *command {
+	for _, s := range c.subs {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// commands returns the full subcommand tree.
+func commands() *command {
+	root := &command{name: "journal"}
+	root.subs = []*command{
+		{
+			name:    "new",
+			summary: "Write the entry for --date (default today)",
+			help: "Opens the journaling TUI. If the entry already exists you are asked\n" +
+				"whether to edit it, edit only mood/energy/highlight, or start fresh.\n" +
+				"Use --date to write a missed entry, e.g. --date yesterday; the backlog\n" +
+				"is carried over from the most recent entry before that date. Future\n" +
+				"dates are rejected.",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					if err := maxArgs(args, 0); err != nil {
+						return err
+					}
+					return app.Run(opts)
+				}
+			},
+		},
+		{
+			name:    "edit",
+			args:    "[date]",
+			summary: "Edit an existing entry in the TUI",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					opts, err := withDateArg(opts, args)
+					if err != nil {
+						return err
+					}
+					return app.Edit(opts)
+				}
+			},
+		},
+		{
+			name:    "todos",
+			args:    "[date]",
+			summary: "Update the todos of an entry from the terminal",
+			help: "Prompts for each todo of the entry, one by one:\n" +
+				"  c, complete   mark todo complete\n" +
+				"  p, partial    mark as in progress ([/])\n" +
+				"  x, cancel     mark as cancelled ([-]); it is not carried forward\n" +
+				"  d, defer      mark as deferred ([>]); it is carried forward\n" +
+				"  n, not        move todo to backlog (carried forward to the next day)\n" +
+				"  anything else leaves the todo unchanged",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					opts, err := withDateArg(opts, args)
+					if err != nil {
+						return err
+					}
+					return app.UpdateTodos(opts)
+				}
+			},
+			subs: []*command{
+				{
+					name:    "list",
+					args:    "[range]",
+					summary: "List todos across the journal",
+					help: "Reads every daily entry, or those in the range (e.g. 2025-12, last week,\n" +
+						"2025-12-01..yesterday), and lists each todo once, as the latest entry\n" +
+						"that has it leaves it. Open todos are listed by default; --status takes\n" +
+						"a comma-separated list of open, in-progress, done, cancelled, deferred\n" +
+						"or all, where open includes in-progress and deferred todos.",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						var q app.TodoQuery
+						status := fs.String("status", "open", "Statuses to list, comma-separated")
+						fs.BoolVar(&q.Overdue, "overdue", false, "Only open todos past their due date")
+						fs.BoolVar(&q.Stale, "stale", false, "Only open todos carried over for stale_todo_days or more")
+						fs.StringVar(&q.Tag, "tag", "", "Only todos with this tag or one nested in it")
+						fs.StringVar(&q.Text, "text", "", "Only todos whose text contains this (ignoring case)")
+						fs.StringVar(&q.Format, "format", "table", "Output format: table, json or markdown")
+						return func(opts app.Options, args []string) error {
+							q.Range = strings.Join(args, " ")
+							q.Statuses = strings.Split(*status, ",")
+							return app.ListTodos(opts, q)
+						}
+					},
+				},
+			},
+		},
+		{
+			name:    "show",
+			args:    "[date]",
+			summary: "Print an entry's Markdown",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					opts, err := withDateArg(opts, args)
+					if err != nil {
+						return err
+					}
+					return app.Show(opts)
+				}
+			},
+		},
+		{
+			name:    "search",
+			args:    "<query>",
+			summary: "Search the text of every entry",
+			help: "Words and \"quoted phrases\" match any section of an entry, ignoring\n" +
+				"case; all must match unless joined by OR. NOT or a leading - excludes\n" +
+				"a term, and parentheses group terms. Filters:\n" +
+				"  mood:, energy:, highlight:, todo:  text in that field\n" +
+				"  template:<name>                    entries written with the template\n" +
+				"  date:<range>                       e.g. date:2025-12, date:\"last week\"\n" +
+				"  section:<id or title>              look for words only in matching sections\n" +
+				"Example: journal search migration -postgres section:learned date:2025",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				limit := fs.Int("limit", 0, "Show at most this many entries (0 for all)")
+				return func(opts app.Options, args []string) error {
+					if len(args) == 0 {
+						return usageError("expected a search query")
+					}
+					return app.Search(opts, strings.Join(args, " "), *limit)
+				}
+			},
+		},
+		{
+			name:    "browse",
+			summary: "Browse and search past entries",
+			help: "Lists the daily entries by month next to a preview of the selected one.\n" +
+				"Keys: up/down or j/k move, / searches as you type (words may be\n" +
+				"abbreviated, e.g. mgrtn for migration), Enter or e edits the entry,\n" +
+				"t updates its todos, ctrl+d/ctrl+u scroll the preview, q quits.",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					if err := maxArgs(args, 0); err != nil {
+						return err
+					}
+					return app.Browse(opts)
+				}
+			},
+		},
+		{
+			name:    "calendar",
+			args:    "[date]",
+			summary: "Show which days have an entry on a calendar",
+			help: "Opens on the month of the date, default today. Arrow keys or h/j/k/l move\n" +
+				"the selected day, [ and ] page, v switches between the month and a\n" +
+				"contribution-style year grid and c cycles what days are coloured by.",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				year := fs.Bool("year", false, "Start on the year grid")
+				by := fs.String("by", "entries", "Colour days by entries, mood, energy or length")
+				return func(opts app.Options, args []string) error {
+					opts, err := withDateArg(opts, args)
+					if err != nil {
+						return err
+					}
+					return app.ShowCalendar(opts, *year, *by)
+				}
+			},
+		},
+		{
+			name:    "index",
+			summary: "Manage the index used by search and todos list",
+			subs: []*command{
+				{
+					name:    "rebuild",
+					summary: "Index every entry again",
+					help: "The index is kept in the cache directory and brought up to date with\n" +
+						"changed files on every query, so this is only needed if it gets out of\n" +
+						"step, e.g. when files are edited without changing their size and time.",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						return func(opts app.Options, args []string) error {
+							if err := maxArgs(args, 0); err != nil {
+								return err
+							}
+							return app.RebuildIndex(opts)
+						}
+					},
+				},
+			},
+		},
+		{
+			name:    "review",
+			args:    "[week|month|year] [date]",
+			summary: "Write the review of a week, month or year",
+			help: "Opens the review entry (YYYY-Www.md, YYYY-MM.md or YYYY.md) for the period\n" +
+				"containing the date, default today. Without a period the date can name\n" +
+				"one, e.g. 'journal review 2025-12' or 'journal review last week';\n" +
+				"otherwise the period is a week. A summary of the period's daily entries\n" +
+				"is shown before the questions of a template with a matching 'period'.",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					period := domain.PeriodDay // Taken from the date
+					if len(args) > 0 {
+						if p, err := domain.ParsePeriod(args[0]); err == nil && p != domain.PeriodDay {
+							period, args = p, args[1:]
+						}
+					}
+					if len(args) > 0 {
+						opts.Date = strings.Join(args, " ")
+					}
+					return app.Review(opts, period)
+				}
+			},
+		},
+		{
+			name:    "stats",
+			summary: "Print journaling statistics",
+			help: "Streaks, entries per week and month, words per entry, the share of\n" +
+				"required questions answered and of todos done, with a row per month.\n" +
+				"Streaks count days in a row with an entry; the current one is kept\n" +
+				"until the end of a day without an entry.",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				span := fs.String("range", "", "Only entries in this date range, e.g. 2025, \"last month\", 2025-06-01..yesterday (default all)")
+				asJSON := fs.Bool("json", false, "Print the statistics as JSON")
+				return func(opts app.Options, args []string) error {
+					if err := maxArgs(args, 0); err != nil {
+						return err
+					}
+					return app.PrintStats(opts, *span, *asJSON)
+				}
+			},
+		},
+		{
+			name:    "templates",
+			summary: "Inspect journal templates",
+			subs: []*command{
+				{
+					name:    "list",
+					summary: "List available templates",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						return func(opts app.Options, args []string) error {
+							if err := maxArgs(args, 0); err != nil {
+								return err
+							}
+							return app.ListTemplates(opts)
+						}
+					},
+				},
+				{
+					name:    "show",
+					args:    "<name>",
+					summary: "Print the questions of a template",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						return func(opts app.Options, args []string) error {
+							if len(args) != 1 {
+								return usageError("expected exactly one template name")
+							}
+							return app.ShowTemplate(opts, args[0])
+						}
+					},
+				},
+				{
+					name:    "validate",
+					args:    "[file...]",
+					summary: "Check templates for errors",
+					help: "Reports YAML errors, unknown keys, duplicate template names and\n" +
+						"duplicate or missing question ids with their file, line and column.\n" +
+						"Without files, every template in the templates folder is checked.",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						strict := fs.Bool("strict", false, "Fail on warnings too")
+						return func(opts app.Options, args []string) error {
+							return app.ValidateTemplates(opts, args, *strict)
+						}
+					},
+				},
+			},
+		},
+		{
+			name:    "migrate",
+			summary: "Upgrade existing entries to the current file format",
+			subs: []*command{
+				{
+					name:    "ids",
+					summary: "Record template question IDs in existing entries",
+					help: "Answers are matched to the questions of the entry's template by title.\n" +
+						"Run this before retitling questions so past answers keep their question.",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						dryRun := fs.Bool("dry-run", false, "List the entries that would change without writing them")
+						return func(opts app.Options, args []string) error {
+							if err := maxArgs(args, 0); err != nil {
+								return err
+							}
+							return app.MigrateIDs(opts, *dryRun)
+						}
+					},
+				},
+				{
+					name:    "layout",
+					summary: "Move daily entries into the folders set by path_pattern",
+					help: "Entries laid out by --from (default YYYY-MM-DD, the flat layout) are\n" +
+						"moved to where path_pattern in config.yaml puts them. Existing files are\n" +
+						"never overwritten and folders left empty are removed. Set path_pattern\n" +
+						"first, e.g. path_pattern: YYYY/MM/YYYY-MM-DD, then run with --dry-run.",
+					setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+						from := fs.String("from", "", "Path pattern the entries are laid out by now (default YYYY-MM-DD)")
+						dryRun := fs.Bool("dry-run", false, "List the moves without making them")
+						return func(opts app.Options, args []string) error {
+							if err := maxArgs(args, 0); err != nil {
+								return err
+							}
+							return app.MigrateLayout(opts, *from, *dryRun)
+						}
+					},
+				},
+			},
+		},
+		{
+			name:    "completion",
+			args:    "<bash|zsh|fish>",
+			summary: "Generate a shell completion script",
+			help: "Load completions in the current shell with, for example:\n" +
+				"  source <(journal completion bash)\n" +
+				"  journal completion zsh > \"${fpath[1]}/_journal\"\n" +
+				"  journal completion fish > ~/.config/fish/completions/journal.fish",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					if len(args) != 1 {
+						return usageError("expected a shell name: bash, zsh or fish")
+					}
+					return writeCompletion(os.Stdout, root, args[0])
+				}
+			},
+		},
+		{
+			name:    "version",
+			summary: "Print the version",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					fmt.Printf("journal-cli version %s\n", Version)
+					return nil
+				}
+			},
+		},
+		{
+			name:    "help",
+			args:    "[command]",
+			summary: "Show help for a command",
+			setup: func(fs *flag.FlagSet) func(app.Options, []string) error {
+				return func(opts app.Options, args []string) error {
+					target, path := root, []string{root.name}
+					for _, a := range args {
+						next := target.sub(a)
+						if next == nil {
+							return usageError(fmt.Sprintf("unknown command %q", strings.Join(append(path[1:], a), " ")))
+						}
+						target, path = next, append(path, a)
+					}
+					printCommandUsage(os.Stdout, target, path)
+					return nil
+				}
+			},
+		},
+	}
+	return root
+}
+
+// usageErr marks errors caused by invalid command-line input; the caller
+// prints the command usage along with the message.
+type usageErr struct{ msg string }
+
+func (e *usageErr) Error() string { return e.msg }
+
+func usageError(msg string) error {
+	return &usageErr{msg: msg}
+}
+
+func maxArgs(args []string, n int) error {
+	if len(args) > n {
+		return usageError(fmt.Sprintf("unexpected arguments: %s", strings.Join(args[n:], " ")))
+	}
+	return nil
+}
+
+// withDateArg applies an optional positional date argument, which takes
+// precedence over the global --date flag.
+func withDateArg(opts app.Options, args []string) (app.Options, error) {
+	if err := maxArgs(args, 1); err != nil {
+		return opts, err
+	}
+	if len(args) == 1 {
+		opts.Date = args[0]
+	}
+	return opts, nil
+}
+
+// addGlobalFlags registers the flags shared by every command.
+func addGlobalFlags(fs *flag.FlagSet, opts *app.Options) {
+	fs.StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to config.yaml (default: OS config dir)")
+	fs.StringVar(&opts.Vault, "vault", opts.Vault, "Obsidian vault path (overrides obsidian_vault)")
+	fs.StringVar(&opts.Date, "date", opts.Date, "Entry date: YYYY-MM-DD, yesterday, -2d, last friday, ... (default today)")
+}
+
+// newFlagSet builds the flag set of a leaf command, including global flags,
+// and returns the action to run after parsing.
+func newFlagSet(c *command, path []string, opts *app.Options) (*flag.FlagSet, func(app.Options, []string) error) {
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addGlobalFlags(fs, opts)
+	var action func(app.Options, []string) error
+	if c.setup != nil {
+		action = c.setup(fs)
+	}
+	return fs, action
+}
+
+// parseInterspersed parses flags that may appear before, after or between
+// positional arguments and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func printCommandUsage(w io.Writer, c *command, path []string) {
+	if len(c.subs) > 0 && c.setup == nil {
+		fmt.Fprintf(w, "Usage: %s <command> [flags]\n\n", strings.Join(path, " "))
+		if c.summary != "" {
+			fmt.Fprintf(w, "%s\n\n", c.summary)
+		}
+		fmt.Fprintf(w, "Commands:\n")
+		for _, s := range c.subs {
+			fmt.Fprintf(w, "  %-12s %s\n", s.name, s.summary)
+		}
+		fmt.Fprintf(w, "\nRun '%s help <command>' for details on a command.\n", path[0])
+		if len(path) == 1 {
+			printGlobalFlags(w)
+			printConfigHelp(w)
+		}
+		return
+	}
+
+	synopsis := strings.Join(path, " ") + " [flags]"
+	if c.args != "" {
+		synopsis += " " + c.args
+	}
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", synopsis, c.summary)
+	if c.help != "" {
+		fmt.Fprintf(w, "\n%s\n", c.help)
+	}
+	if len(c.subs) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		for _, s := range c.subs {
+			fmt.Fprintf(w, "  %-12s %s\n", s.name, s.summary)
+		}
+	}
+
+	var opts app.Options
+	fs, _ := newFlagSet(c, path, &opts)
+	fmt.Fprintf(w, "\nFlags:\n")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func printGlobalFlags(w io.Writer) {
+	var opts app.Options
+	fs := flag.NewFlagSet("journal", flag.ContinueOnError)
+	addGlobalFlags(fs, &opts)
+	fmt.Fprintf(w, "\nGlobal flags (accepted by every command):\n")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func printConfigHelp(w io.Writer) {
+	fmt.Fprintf(w, "\nConfiguration:\n")
+	fmt.Fprintf(w, "  The application looks for a config.yaml file in:\n")
+	fmt.Fprintf(w, "  - macOS:   ~/Library/Application Support/journal-cli/config.yaml\n")
+	fmt.Fprintf(w, "  - Linux:   ~/.config/journal-cli/config.yaml\n")
+	fmt.Fprintf(w, "  - Windows: %%APPDATA%%\\journal-cli\\config.yaml\n")
+	fmt.Fprintf(w, "\n  Example config.yaml:\n")
+	fmt.Fprintf(w, "    obsidian_vault: \"/Users/username/Documents/ObsidianVault\"\n")
+	fmt.Fprintf(w, "    journal_dir: \"Journal/Daily\" # Relative to obsidian_vault\n")
+	fmt.Fprintf(w, "    path_pattern: YYYY/MM/YYYY-MM-DD # Daily entry paths in journal_dir\n")
+	fmt.Fprintf(w, "    backlog_lookback_days: 30 # How far back to look for the previous entry\n")
+	fmt.Fprintf(w, "    stale_todo_days: 7 # Flag todos carried over for this long\n")
+	fmt.Fprintf(w, "    default_template: daily-human-dev\n")
+	fmt.Fprintf(w, "    template_rules: # First match wins\n")
+	fmt.Fprintf(w, "      - days: weekends\n")
+	fmt.Fprintf(w, "        template: gentle-day\n")
+	fmt.Fprintf(w, "    recurring_todos: # Added to new entries on matching dates\n")
+	fmt.Fprintf(w, "      - text: Plan the week\n")
+	fmt.Fprintf(w, "        days: mon\n")
+	fmt.Fprintf(w, "      - text: Sprint review\n")
+	fmt.Fprintf(w, "        rule: FREQ=WEEKLY;INTERVAL=2;BYDAY=FR # iCalendar RRULE\n")
+	fmt.Fprintf(w, "        start: 2026-01-09\n\n")
+	fmt.Fprintf(w, "Templates:\n")
+	fmt.Fprintf(w, "  Templates are YAML files stored in the 'templates' subdirectory of the config folder.\n")
+	fmt.Fprintf(w, "  Example template:\n")
+	fmt.Fprintf(w, "    name: daily-reflection\n")
+	fmt.Fprintf(w, "    description: A simple daily reflection\n")
+	fmt.Fprintf(w, "    questions:\n")
+	fmt.Fprintf(w, "      - id: gratitude\n")
+	fmt.Fprintf(w, "        title: \"What are you grateful for?\"\n")
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"journal-cli/internal/app"
+)
+
+// completionNode is a flattened view of a command used to generate shell
+// completion scripts.
+type completionNode struct {
+	path    []string // command words after "journal"
+	subs    []*command
+	flags   []*flag.Flag
+	summary string
+}
+
+// valueFlags are flags whose next word is a value rather than a command.
+func valueFlags(nodes []completionNode) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, n := range nodes {
+		for _, f := range n.flags {
+			if isBoolFlag(f) || seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			out = append(out, "--"+f.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func completionNodes(root *command) []completionNode {
+	var nodes []completionNode
+	var walk func(c *command, path []string)
+	walk = func(c *command, path []string) {
+		var opts app.Options
+		fs, _ := newFlagSet(c, append([]string{root.name}, path...), &opts)
+		n := completionNode{path: path, subs: c.subs, summary: c.summary}
+		fs.VisitAll(func(f *flag.Flag) { n.flags = append(n.flags, f) })
+		nodes = append(nodes, n)
+		for _, s := range c.subs {
+			walk(s, append(append([]string{}, path...), s.name))
+		}
+	}
+	walk(root, nil)
+	return nodes
+}
+
+func (n completionNode) words() []string {
+	var words []string
+	for _, s := range n.subs {
+		words = append(words, s.name)
+	}
+	for _, f := range n.flags {
+		words = append(words, "--"+f.Name)
+	}
+	return words
+}
+
+func writeCompletion(w io.Writer, root *command, shell string) error {
+	nodes := completionNodes(root)
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, nodes)
+	case "zsh":
+		writeZshCompletion(w, nodes)
+	case "fish":
+		writeFishCompletion(w, nodes)
+	default:
+		return usageError(fmt.Sprintf("unsupported shell %q (use bash, zsh or fish)", shell))
+	}
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, nodes []completionNode) {
+	fmt.Fprintf(w, "# bash completion for journal\n")
+	fmt.Fprintf(w, "_journal() {\n")
+	fmt.Fprintf(w, "    local cur prev path=\"\" i word skip=0\n")
+	fmt.Fprintf(w, "    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(w, "    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "    case \"$prev\" in\n")
+	fmt.Fprintf(w, "        --config|--vault)\n")
+	fmt.Fprintf(w, "            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	fmt.Fprintf(w, "            return ;;\n")
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "    for ((i=1; i<COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(w, "        word=\"${COMP_WORDS[i]}\"\n")
+	fmt.Fprintf(w, "        if [[ $skip == 1 ]]; then skip=0; continue; fi\n")
+	fmt.Fprintf(w, "        case \"$word\" in\n")
+	fmt.Fprintf(w, "            %s) skip=1; continue ;;\n", strings.Join(valueFlags(nodes), "|"))
+	fmt.Fprintf(w, "            -*) continue ;;\n")
+	fmt.Fprintf(w, "        esac\n")
+	fmt.Fprintf(w, "        path=\"${path:+$path }$word\"\n")
+	fmt.Fprintf(w, "    done\n")
+	fmt.Fprintf(w, "    case \"$path\" in\n")
+	for _, n := range nodes {
+		fmt.Fprintf(w, "        %q) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n",
+			strings.Join(n.path, " "), strings.Join(n.words(), " "))
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -F _journal journal\n")
+}
+
+func writeZshCompletion(w io.Writer, nodes []completionNode) {
+	fmt.Fprintf(w, "#compdef journal\n\n")
+	fmt.Fprintf(w, "_journal() {\n")
+	fmt.Fprintf(w, "    local cmdpath=\"\" word skip=0\n")
+	fmt.Fprintf(w, "    local -a cmds\n")
+	fmt.Fprintf(w, "    case \"${words[CURRENT-1]}\" in\n")
+	fmt.Fprintf(w, "        --config|--vault) _files; return ;;\n")
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "    for word in \"${(@)words[2,CURRENT-1]}\"; do\n")
+	fmt.Fprintf(w, "        if (( skip )); then skip=0; continue; fi\n")
+	fmt.Fprintf(w, "        case \"$word\" in\n")
+	fmt.Fprintf(w, "            %s) skip=1; continue ;;\n", strings.Join(valueFlags(nodes), "|"))
+	fmt.Fprintf(w, "            -*) continue ;;\n")
+	fmt.Fprintf(w, "        esac\n")
+	fmt.Fprintf(w, "        cmdpath=\"${cmdpath:+$cmdpath }$word\"\n")
+	fmt.Fprintf(w, "    done\n")
+	fmt.Fprintf(w, "    case \"$cmdpath\" in\n")
+	for _, n := range nodes {
+		fmt.Fprintf(w, "        %q)\n", strings.Join(n.path, " "))
+		if len(n.subs) > 0 {
+			fmt.Fprintf(w, "            cmds=(\n")
+			for _, s := range n.subs {
+				fmt.Fprintf(w, "                %s\n", shellQuote(s.name+":"+s.summary))
+			}
+			fmt.Fprintf(w, "            )\n")
+			fmt.Fprintf(w, "            _describe 'command' cmds\n")
+		}
+		var flags []string
+		for _, f := range n.flags {
+			flags = append(flags, "--"+f.Name)
+		}
+		fmt.Fprintf(w, "            compadd -- %s\n", strings.Join(flags, " "))
+		fmt.Fprintf(w, "            ;;\n")
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "compdef _journal journal\n")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeFishCompletion(w io.Writer, nodes []completionNode) {
+	fmt.Fprintf(w, "# fish completion for journal\n")
+	fmt.Fprintf(w, "complete -c journal -f\n")
+	for _, n := range nodes {
+		cond := "__fish_use_subcommand"
+		if len(n.path) > 0 {
+			cond = "__fish_seen_subcommand_from " + n.path[len(n.path)-1]
+		}
+		for _, s := range n.subs {
+			fmt.Fprintf(w, "complete -c journal -n %s -a %s -d %s\n",
+				shellQuote(cond), s.name, shellQuote(s.summary))
+		}
+	}
+	// Register each flag once; fish offers them wherever a flag is typed.
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		for _, f := range n.flags {
+			if seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			line := fmt.Sprintf("complete -c journal -l %s -d %s", f.Name, shellQuote(f.Usage))
+			if f.Name == "config" || f.Name == "vault" {
+				line += " -r -F"
+			} else if !isBoolFlag(f) {
+				line += " -r"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
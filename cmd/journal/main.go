@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"journal-cli/internal/app"
@@ -11,66 +13,101 @@ import (
 const Version = "1.0.0"
 
 func main() {
-	help := flag.Bool("help", false, "Show help message")
-	version := flag.Bool("version", false, "Show version")
-	todos := flag.String("todos", "", "Update todos for a date (YYYY-MM-DD). Empty = today")
-	todoFlag := flag.Bool("todo", false, "Update today's todos (shorthand for --todos \"\")")
-
-	// Custom usage message
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "A cross-platform terminal-based daily journaling application.\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nConfiguration:\n")
-		fmt.Fprintf(os.Stderr, "  The application looks for a config.yaml file in:\n")
-		fmt.Fprintf(os.Stderr, "  - macOS:   ~/Library/Application Support/journal-cli/config.yaml\n")
-		fmt.Fprintf(os.Stderr, "  - Linux:   ~/.config/journal-cli/config.yaml\n")
-		fmt.Fprintf(os.Stderr, "  - Windows: %%APPDATA%%\\journal-cli\\config.yaml\n")
-		fmt.Fprintf(os.Stderr, "\n  Example config.yaml:\n")
-		fmt.Fprintf(os.Stderr, "    obsidian_vault: \"/Users/username/Documents/ObsidianVault\"\n")
-		fmt.Fprintf(os.Stderr, "    journal_dir: \"Journal/Daily\" # Relative to obsidian_vault\n\n")
-		fmt.Fprintf(os.Stderr, "Templates:\n")
-		fmt.Fprintf(os.Stderr, "  Templates are YAML files stored in the 'templates' subdirectory of the config folder.\n")
-		fmt.Fprintf(os.Stderr, "  Example template:\n")
-		fmt.Fprintf(os.Stderr, "    name: daily-reflection\n")
-		fmt.Fprintf(os.Stderr, "    description: A simple daily reflection\n")
-		fmt.Fprintf(os.Stderr, "    questions:\n")
-		fmt.Fprintf(os.Stderr, "      - id: gratitude\n")
-		fmt.Fprintf(os.Stderr, "        title: \"What are you grateful for?\"\n")
-
-		fmt.Fprintf(os.Stderr, "\nTodo updater:\n")
-		fmt.Fprintf(os.Stderr, "  Use --todos [YYYY-MM-DD] to run a quick CLI updater for todos (empty = today).\n")
-		fmt.Fprintf(os.Stderr, "  Examples:\n")
-		fmt.Fprintf(os.Stderr, "    ./journal --todos \"\"    # update today's todos\n")
-		fmt.Fprintf(os.Stderr, "    ./journal --todos 2025-12-30  # update todos for that date\n")
+	if err := execute(os.Args[1:]); err != nil {
+		if errors.Is(err, app.ErrCancelled) {
+			fmt.Println("Journaling cancelled.")
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+}
+
+// execute parses global flags, resolves the subcommand and runs it. With no
+// subcommand the journaling TUI is opened, as before subcommands existed.
+func execute(args []string) error {
+	root := commands()
 
-	flag.Parse()
+	var opts app.Options
+	global := flag.NewFlagSet("journal", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	addGlobalFlags(global, &opts)
+	version := global.Bool("version", false, "Show version")
+	// Deprecated flags kept for scripts written against the flat CLI.
+	todos := global.String("todos", "", "Deprecated: use 'journal todos [date]'")
+	todoFlag := global.Bool("todo", false, "Deprecated: use 'journal todos'")
 
-	if *help {
-		flag.Usage()
-		return
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printCommandUsage(os.Stdout, root, []string{root.name})
+			return nil
+		}
+		printCommandUsage(os.Stderr, root, []string{root.name})
+		return err
 	}
 
 	if *version {
 		fmt.Printf("journal-cli version %s\n", Version)
-		return
+		return nil
 	}
 
-	// Run the todo updater only when explicitly requested
-	if *todos != "" || *todoFlag {
-		// If --todo boolean is set, pass empty string to mean today
-		arg := *todos
-		if *todoFlag {
-			arg = ""
+	legacyTodos := *todoFlag
+	global.Visit(func(f *flag.Flag) {
+		if f.Name == "todos" {
+			legacyTodos = true
 		}
-		if err := app.UpdateTodos(arg); err != nil {
-			fmt.Fprintf(os.Stderr, "Error updating todos: %v\n", err)
-			os.Exit(1)
+	})
+	if legacyTodos {
+		if !*todoFlag {
+			opts.Date = *todos
 		}
-		return
+		return app.UpdateTodos(opts)
+	}
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		rest = []string{"new"}
 	}
 
-	app.Run()
+	// Walk down the tree until we reach a leaf command, or a command with
+	// an action of its own that is not followed by one of its subcommands.
+	c, path := root, []string{root.name}
+	for len(c.subs) > 0 {
+		var next *command
+		if len(rest) > 0 {
+			next = c.sub(rest[0])
+		}
+		if next == nil && c.setup != nil {
+			break
+		}
+		if len(rest) == 0 {
+			printCommandUsage(os.Stderr, c, path)
+			return usageError(fmt.Sprintf("%s requires a subcommand", path[len(path)-1]))
+		}
+		if next == nil {
+			printCommandUsage(os.Stderr, c, path)
+			return usageError(fmt.Sprintf("unknown command %q", rest[0]))
+		}
+		c, path, rest = next, append(path, rest[0]), rest[1:]
+	}
+
+	fs, action := newFlagSet(c, path, &opts)
+	positional, err := parseInterspersed(fs, rest)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printCommandUsage(os.Stdout, c, path)
+			return nil
+		}
+		printCommandUsage(os.Stderr, c, path)
+		return err
+	}
+
+	if err := action(opts, positional); err != nil {
+		var ue *usageErr
+		if errors.As(err, &ue) {
+			printCommandUsage(os.Stderr, c, path)
+		}
+		return err
+	}
+	return nil
 }
@@ -1,122 +1,163 @@
 package app
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"slices"
 	"time"
 
 	"journal-cli/internal/config"
+	"journal-cli/internal/dateexpr"
 	"journal-cli/internal/domain"
-	"journal-cli/internal/fs"
 	"journal-cli/internal/markdown"
 	"journal-cli/internal/stats"
 	"journal-cli/internal/template"
 	"journal-cli/internal/todo"
 	"journal-cli/internal/tui"
-
-	tea "github.com/charmbracelet/bubbletea"
 )
 
-func Run() {
+// ErrCancelled is returned when the user quits the TUI before finishing.
+var ErrCancelled = errors.New("journaling cancelled")
+
+// Run opens the journaling TUI for the date selected by opts (today by
+// default). If an entry already exists the user is asked whether to edit it
+// or start fresh. Future dates are rejected.
+func Run(opts Options) error {
+	return run(opts, false)
+}
+
+// Edit opens the journaling TUI on an existing entry. Unlike Run it fails
+// when no entry exists for the selected date.
+func Edit(opts Options) error {
+	return run(opts, true)
+}
+
+func run(opts Options, editOnly bool) error {
+	opts = opts.withDefaults()
+	fsys, out := opts.FS, opts.Stdout
+
 	// 1. Load Config
-	cfg, err := config.LoadConfig()
+	cfg, err := loadConfig(opts)
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
 	// 2. Load Templates
-	templates, err := template.LoadTemplates()
+	templates, diags, err := loadTemplates(opts)
 	if err != nil {
-		fmt.Printf("Error loading templates: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	templates = template.ForPeriod(templates, domain.PeriodDay)
+	if len(templates) == 0 {
+		for _, d := range diags {
+			fmt.Fprintln(out, d)
+		}
+		return fmt.Errorf("no usable templates in %s", templatesDir(opts))
 	}
 
 	// 3. Setup Date and Paths
-	now := time.Now()
-	journalDir := cfg.JournalDir
-	if cfg.ObsidianVault != "" {
-		journalDir = filepath.Join(cfg.ObsidianVault, cfg.JournalDir)
-	} else {
-		// Fallback if not set, though LoadConfig sets a default relative path
-		// But we need a base. Let's assume current directory if Vault is empty?
-		// Or better, warn user.
-		// For MVP, if Vault is empty, we might just use current directory or a default in UserHome.
-		// Let's use a default in UserHome/Documents/Journal if not set?
-		// Or just fail?
-		// The prompt says "Stored in <ObsidianVault>/Journal/Daily/"
-		// If ObsidianVault is not configured, we can't really guess.
-		// But for now, let's just use a local "journal" folder if not set.
-		if journalDir == "Journal/Daily" { // Default value from config.go
-			// Try to find a good place
-			home, _ := os.UserHomeDir()
-			journalDir = filepath.Join(home, "Documents", "Journal", "Daily")
-		}
+	now, err := resolveDate(opts)
+	if err != nil {
+		return err
+	}
+	today := dateexpr.Day(opts.Clock.Now())
+	if now.After(today) {
+		return fmt.Errorf("cannot journal for a future date (%s)", now.Format("2006-01-02"))
+	}
+	backdated := now.Before(today)
+	journalDir := resolveJournalDir(cfg)
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
 	}
 
-	if err := fs.EnsureDir(journalDir); err != nil {
-		fmt.Printf("Error ensuring journal directory: %v\n", err)
-		os.Exit(1)
+	if err := fsys.MkdirAll(journalDir); err != nil {
+		return fmt.Errorf("ensure journal directory: %w", err)
 	}
 
-	todayFile := filepath.Join(journalDir, now.Format("2006-01-02")+".md")
+	todayFile := l.Path(journalDir, now)
+	if editOnly && !fsys.Exists(todayFile) {
+		return fmt.Errorf("journal file not found: %s", todayFile)
+	}
 
-	// 4. Load Backlog
-	yesterdayFile := todo.GetPreviousJournalPath(journalDir, now)
-	backlog, err := todo.GetBacklog(yesterdayFile)
-	if err != nil {
-		// Non-fatal, just log or ignore
-		fmt.Printf("Warning: could not load backlog: %v\n", err)
+	// 4. Load Backlog from the most recent entry before the entry date
+	// (not the wall clock), so days off do not drop open todos.
+	backlog := []domain.Todo{}
+	var backlogFrom time.Time
+	if previousFile, date, ok := todo.FindPreviousJournal(fsys, journalDir, l, now, cfg.BacklogLookback()); ok {
+		backlog, err = todo.GetBacklog(fsys, previousFile)
+		if err != nil {
+			// Non-fatal, just log or ignore
+			fmt.Fprintf(out, "Warning: could not load backlog: %v\n", err)
+		}
+		backlog, backlogFrom = todo.CarryOver(backlog, now), date
 	}
 
 	// 5. Initialize Entry
+	// New entries start with the backlog and the recurring todos due on the
+	// entry date; reopened entries keep the todos they were saved with.
+	newEntry := func() *domain.JournalEntry {
+		e := domain.NewJournalEntry(now, "")
+		e.Backlog, e.BacklogFrom = backlog, backlogFrom
+		addRecurring(e, cfg)
+		return e
+	}
 	var entry *domain.JournalEntry
 
 	// If today's journal file exists, load it and start in edit mode
 	editFields := false
-	if fs.Exists(todayFile) {
-		data, err := fs.ReadFile(todayFile)
+	if fsys.Exists(todayFile) {
+		data, err := fsys.ReadFile(todayFile)
 		if err != nil {
-			fmt.Printf("Warning: could not read today's file: %v\n", err)
-			entry = domain.NewJournalEntry(now, "")
-			entry.Backlog = backlog
+			fmt.Fprintf(out, "Warning: could not read today's file: %v\n", err)
+			entry = newEntry()
 		} else {
 			parsed, err := markdown.ParseMarkdown(data)
 			if err != nil {
-				fmt.Printf("Warning: could not parse today's file, starting fresh: %v\n", err)
-				entry = domain.NewJournalEntry(now, "")
-				entry.Backlog = backlog
+				fmt.Fprintf(out, "Warning: could not parse today's file, starting fresh: %v\n", err)
+				entry = newEntry()
 			} else {
 				// Use parsed entry as starting point
 				entry = parsed
 				// Ensure Backlog from yesterday is present too (merge if missing)
 				if len(entry.Backlog) == 0 {
-					entry.Backlog = backlog
+					entry.Backlog, entry.BacklogFrom = backlog, backlogFrom
 				}
 			}
 		}
 	} else {
-		entry = domain.NewJournalEntry(now, "")
-		entry.Backlog = backlog
+		entry = newEntry()
 	}
 
-	// 6. Stats
-	s, err := stats.GetStats(journalDir)
-	if err != nil {
-		fmt.Printf("Warning: could not calculate stats: %v\n", err)
+	entry.DedupeTodos()
+
+	// 6. Stats, and the calendar, from the index of the journal
+	var (
+		s    stats.Stats
+		days stats.Calendar
+	)
+	if x, err := openIndex(opts, journalDir); err != nil {
+		fmt.Fprintf(out, "Warning: could not calculate stats: %v\n", err)
+	} else {
+		daily := x.Daily(l)
+		s, days = stats.GetStats(daily, today), stats.NewCalendar(daily)
 	}
 
 	// 7. Initialize TUI
 	// If today's file existed and was parsed, prompt the user whether to edit it
 	// or start fresh. Offer an option to edit fields (mood/energy/highlight) directly.
-	if fs.Exists(todayFile) {
+	// Edit skips the prompt and always edits the full entry.
+	if !editOnly && fsys.Exists(todayFile) {
 		// Prompt the user
-		fmt.Printf("Today's journal exists at %s.\n", todayFile)
-		fmt.Printf("[Enter] Edit full entry  |  f Edit mood/energy/highlight  |  n Start fresh\n")
-		fmt.Printf("Choose an option: ")
+		if backdated {
+			fmt.Fprintf(out, "The journal for %s exists at %s.\n", now.Format("Monday, 02 Jan 2006"), todayFile)
+		} else {
+			fmt.Fprintf(out, "Today's journal exists at %s.\n", todayFile)
+		}
+		fmt.Fprintf(out, "[Enter] Edit full entry  |  f Edit mood/energy/highlight  |  n Start fresh\n")
+		fmt.Fprintf(out, "Choose an option: ")
 		var resp string
-		_, err := fmt.Scanln(&resp)
+		_, err := fmt.Fscanln(opts.Stdin, &resp)
 		if err != nil {
 			// Treat empty input (enter) as default edit
 			resp = ""
@@ -125,8 +166,7 @@ func Run() {
 		switch resp {
 		case "n", "N":
 			// Start fresh: override parsed entry with a new one but keep backlog
-			entry = domain.NewJournalEntry(now, "")
-			entry.Backlog = backlog
+			entry = newEntry()
 		case "f", "F":
 			// Edit fields: ensure entry is used but start at Mood input
 			editFields = true
@@ -136,6 +176,19 @@ func Run() {
 	}
 
 	model := tui.NewModel(cfg, templates, entry, s)
+	model.Backdated = backdated
+	if days != nil {
+		model.Calendar = tui.NewCalendar(days, today)
+		model.Calendar.Cursor = dateexpr.Day(now)
+	}
+	for _, d := range diags {
+		model.TemplateWarnings = append(model.TemplateWarnings, d.Short())
+	}
+
+	// New entries start on the template the config picks for the date.
+	if entry.Template == "" {
+		selectTemplate(&model, cfg, templates, now)
+	}
 
 	// If we loaded an existing entry (from today's file), initialize the UI
 	// so user can edit rather than starting a fresh flow.
@@ -144,6 +197,7 @@ func Run() {
 		for i, t := range templates {
 			if t.Name == entry.Template {
 				model.TemplateCursor = i
+				keyAnswers(entry, t)
 				break
 			}
 		}
@@ -186,18 +240,17 @@ func Run() {
 						qi := 0
 						foundUnanswered := false
 						for i, q := range tmpl.Questions {
-							if _, ok := entry.Questions[q.Title]; !ok || entry.Questions[q.Title] == "" {
+							if !model.QuestionVisible(i) {
+								continue
+							}
+							if answer, _ := entry.Answer(q.Key()); answer == "" {
 								qi = i
 								foundUnanswered = true
 								break
 							}
 						}
 						if foundUnanswered {
-							model.CurrentStep = tui.StepQuestions
-							model.QuestionIndex = qi
-							q := tmpl.Questions[qi].Title
-							model.QuestionInput.SetValue(entry.Questions[q])
-							model.QuestionInput.Focus()
+							model.SetQuestion(qi)
 						} else {
 							model.CurrentStep = tui.StepDone
 						}
@@ -212,24 +265,14 @@ func Run() {
 		}
 	}
 
-	p := tea.NewProgram(model)
-
 	// 8. Run TUI
-	finalModel, err := p.Run()
+	m, err := opts.RunTUI(model)
 	if err != nil {
-		fmt.Printf("Error running TUI: %v\n", err)
-		os.Exit(1)
-	}
-
-	m, ok := finalModel.(tui.Model)
-	if !ok {
-		fmt.Printf("Error: unexpected model type\n")
-		os.Exit(1)
+		return err
 	}
 
 	if m.CurrentStep != tui.StepDone {
-		fmt.Println("Journaling cancelled.")
-		return
+		return ErrCancelled
 	}
 
 	// Merge selected backlog items into Todos
@@ -258,20 +301,115 @@ func Run() {
 
 	m.Entry.Todos = newTodos
 	m.Entry.Backlog = remainingBacklog
+	m.Entry.DedupeTodos()
+	m.Entry.StampTodos()
+
+	applyTemplate(m.Entry, templates)
 
 	// 8. Save to Disk
 	content, err := markdown.GenerateMarkdown(m.Entry)
 	if err != nil {
-		fmt.Printf("Error generating markdown: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("generate markdown: %w", err)
 	}
 
-	if err := fs.WriteFile(todayFile, content); err != nil {
-		fmt.Printf("Error writing file: %v\n", err)
-		os.Exit(1)
+	if err := fsys.WriteFile(todayFile, content); err != nil {
+		return fmt.Errorf("write file: %w", err)
 	}
+	updateIndex(opts, journalDir, todayFile)
 
-	fmt.Printf("Journal entry saved to: %s\n", todayFile)
-	fmt.Printf("To view:  cat \"%s\"\n", todayFile)
-	fmt.Printf("To edit:  nano \"%s\"\n", todayFile)
+	fmt.Fprintf(out, "Journal entry saved to: %s\n", todayFile)
+	fmt.Fprintf(out, "To view:  cat \"%s\"\n", todayFile)
+	fmt.Fprintf(out, "To edit:  nano \"%s\"\n", todayFile)
+	return nil
+}
+
+// addRecurring adds the recurring todos due on the entry's date, skipping
+// those already open in its todos or backlog, such as yesterday's
+// occurrence when it was not done.
+func addRecurring(entry *domain.JournalEntry, cfg *config.Config) {
+	for _, text := range cfg.RecurringFor(entry.Date) {
+		t := domain.Todo{Text: text, Created: entry.Date}
+		open := func(u domain.Todo) bool { return !u.Closed() && u.Text == text }
+		if !slices.ContainsFunc(entry.Todos, open) && !slices.ContainsFunc(entry.Backlog, open) {
+			entry.Todos = append(entry.Todos, t)
+		}
+	}
+}
+
+// selectTemplate applies the config's template rules for date: the
+// matching template is preselected in the list, or picked outright when
+// skip_template_picker is set.
+func selectTemplate(model *tui.Model, cfg *config.Config, templates []template.Template, date time.Time) {
+	name := cfg.TemplateFor(date)
+	if name == "" {
+		return
+	}
+	i := slices.IndexFunc(templates, func(t template.Template) bool { return t.Name == name })
+	if i < 0 {
+		model.TemplateWarnings = append(model.TemplateWarnings,
+			fmt.Sprintf("config.yaml: template %q selected for %s not found", name, date.Format("Mon 2006-01-02")))
+		return
+	}
+	model.SuggestedTemplate = name
+	model.TemplateCursor = i
+	if cfg.SkipTemplatePicker {
+		model.SelectTemplate(i)
+	}
+}
+
+// applyTemplate writes the answers of entry in the order of its template;
+// answers to questions the template no longer asks stay at the end.
+// Numeric answers are also recorded as metrics.
+func applyTemplate(entry *domain.JournalEntry, templates []template.Template) {
+	for _, t := range templates {
+		if t.Name == entry.Template {
+			entry.SortAnswers(questionKeys(t))
+			setMetrics(entry, t)
+			return
+		}
+	}
+}
+
+// keyAnswers matches the answers parsed from a file to the questions of
+// tmpl. Answers are matched by ID, or by title for entries written before
+// the question had an ID; matched answers take the question's current ID
+// and title, so a retitled question keeps its past answers.
+func keyAnswers(entry *domain.JournalEntry, tmpl template.Template) {
+	for i, a := range entry.Answers {
+		for _, q := range tmpl.Questions {
+			if a.ID == q.Key() || a.ID == q.Title {
+				entry.Answers[i].ID = q.Key()
+				entry.Answers[i].Question = q.Title
+				break
+			}
+		}
+	}
+}
+
+// questionKeys returns the answer keys of tmpl's questions in order.
+func questionKeys(tmpl template.Template) []string {
+	keys := make([]string, len(tmpl.Questions))
+	for i, q := range tmpl.Questions {
+		keys[i] = q.Key()
+	}
+	return keys
+}
+
+// setMetrics records the numeric answers to tmpl's questions in
+// entry.Metrics. Metrics of questions tmpl does not ask are left alone.
+func setMetrics(entry *domain.JournalEntry, tmpl template.Template) {
+	if entry.Metrics == nil {
+		entry.Metrics = make(map[string]float64)
+	}
+	for _, q := range tmpl.Questions {
+		if !q.IsNumeric() {
+			continue
+		}
+		answer, _ := entry.Answer(q.Key())
+		if v, ok := q.Metric(answer); ok {
+			entry.Metrics[q.Key()] = v
+		} else {
+			delete(entry.Metrics, q.Key())
+		}
+	}
 }
@@ -0,0 +1,1080 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"journal-cli/internal/clock"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/index"
+	"journal-cli/internal/markdown"
+	"journal-cli/internal/todo"
+	"journal-cli/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	enter    = tea.KeyMsg{Type: tea.KeyEnter}
+	tab      = tea.KeyMsg{Type: tea.KeyTab}
+	shiftTab = tea.KeyMsg{Type: tea.KeyShiftTab}
+	space    = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+)
+
+func typeText(s string) tea.Msg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// scriptedTUI returns a RunTUI replacement that feeds msgs to the model
+// until it reaches StepDone or the script runs out.
+func scriptedTUI(msgs ...tea.Msg) func(tui.Model) (tui.Model, error) {
+	return func(m tui.Model) (tui.Model, error) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(tui.Model)
+			if m.CurrentStep == tui.StepDone {
+				break
+			}
+		}
+		return m, nil
+	}
+}
+
+// newTestEnv seeds an in-memory filesystem with a config file and a single
+// one-question template and returns options pointing at it.
+func newTestEnv(t *testing.T) (Options, *fs.MemFS) {
+	t.Helper()
+	mem := fs.NewMemFS()
+	files := map[string]string{
+		"/cfg/config.yaml": "obsidian_vault: /vault\njournal_dir: Journal\n",
+		"/cfg/templates/simple.yaml": "name: simple\n" +
+			"questions:\n" +
+			"  - id: learned\n" +
+			"    title: What did I learn?\n",
+	}
+	for path, content := range files {
+		if err := mem.WriteFile(filepath.FromSlash(path), []byte(content)); err != nil {
+			t.Fatalf("seed %s: %v", path, err)
+		}
+	}
+	return Options{
+		ConfigPath: filepath.FromSlash("/cfg/config.yaml"),
+		CacheDir:   filepath.FromSlash("/cache"),
+		FS:         mem,
+		Stdin:      strings.NewReader(""),
+		Stdout:     &bytes.Buffer{},
+	}, mem
+}
+
+func at(date string) clock.Clock {
+	d, _ := time.ParseInLocation("2006-01-02 15:04", date+" 09:30", time.Local)
+	return clock.Fixed(d)
+}
+
+func readEntry(t *testing.T, mem *fs.MemFS, date string) string {
+	t.Helper()
+	data, err := mem.ReadFile(filepath.FromSlash("/vault/Journal/" + date + ".md"))
+	if err != nil {
+		t.Fatalf("read entry %s: %v", date, err)
+	}
+	return string(data)
+}
+
+func TestRunNewEntryAndBacklogCarryOver(t *testing.T) {
+	opts, mem := newTestEnv(t)
+
+	// Day 1: write an entry with two todos.
+	day1 := opts
+	day1.Clock = at("2025-12-29")
+	day1.RunTUI = scriptedTUI(
+		enter, // pick the only template
+		typeText("calm"), enter,
+		typeText("high"), enter,
+		typeText("shipped the parser"), enter,
+		typeText("write tests"), enter,
+		typeText("ship it"), enter,
+		enter, // empty todo finishes the list
+		typeText("clocks help"), enter,
+	)
+	if err := Run(day1); err != nil {
+		t.Fatalf("Run day 1: %v", err)
+	}
+
+	entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-29")))
+	if err != nil {
+		t.Fatalf("parse day 1: %v", err)
+	}
+	if entry.Mood != "calm" || entry.Energy != "high" || entry.Highlight != "shipped the parser" {
+		t.Errorf("unexpected fields: mood=%q energy=%q highlight=%q", entry.Mood, entry.Energy, entry.Highlight)
+	}
+	if len(entry.Todos) != 2 {
+		t.Fatalf("expected 2 todos on day 1, got %v", entry.Todos)
+	}
+
+	// Day 2: the unchecked todos come back as backlog; take the first one.
+	day2 := opts
+	day2.Clock = at("2025-12-30")
+	day2.RunTUI = scriptedTUI(
+		enter,
+		typeText("tired"), enter,
+		typeText("low"), enter,
+		typeText("rest"), enter,
+		tab, space, shiftTab, // select the first backlog item
+		enter,
+		typeText("nothing"), enter,
+	)
+	if err := Run(day2); err != nil {
+		t.Fatalf("Run day 2: %v", err)
+	}
+
+	entry, err = markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-30")))
+	if err != nil {
+		t.Fatalf("parse day 2: %v", err)
+	}
+	if len(entry.Todos) != 1 || entry.Todos[0].Text != "write tests" {
+		t.Errorf("expected selected backlog item in todos, got %v", entry.Todos)
+	}
+	if len(entry.Backlog) != 1 || entry.Backlog[0].Text != "ship it" {
+		t.Errorf("expected unselected item to stay in backlog, got %v", entry.Backlog)
+	}
+}
+
+func TestRunBackdatedUsesBacklogRelativeToDate(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	prev := "---\ndate: 2025-12-26\ntemplate: simple\n---\n\n## ✅ Todos – Today\n- [ ] from friday\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-26.md"), []byte(prev)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Clock = at("2025-12-30")
+	opts.Date = "-3d" // Saturday 2025-12-27
+	opts.RunTUI = scriptedTUI(enter, enter, enter, enter, enter, enter)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-27")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entry.Backlog) != 1 || entry.Backlog[0].Text != "from friday" {
+		t.Errorf("expected backlog from 2025-12-26, got %v", entry.Backlog)
+	}
+}
+
+func TestRunBacklogFromMostRecentEntry(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	prev := "---\ndate: 2025-12-26\ntemplate: simple\n---\n\n## ✅ Todos – Today\n- [ ] from friday\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-26.md"), []byte(prev)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Clock = at("2025-12-29") // Monday, nothing written over the weekend
+	opts.RunTUI = scriptedTUI(enter, enter, enter, enter, enter, enter)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	content := readEntry(t, mem, "2025-12-29")
+	if !strings.Contains(content, "## 🔁 Backlog (from 2025-12-26)\n- [ ] from friday [carried:: 1] [carried_on:: 2025-12-29] 🆔 ") {
+		t.Errorf("expected the backlog from friday:\n%s", content)
+	}
+
+	// Beyond backlog_lookback_days nothing is carried over.
+	config := "obsidian_vault: /vault\njournal_dir: Journal\nbacklog_lookback_days: 1\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/config.yaml"), []byte(config)); err != nil {
+		t.Fatal(err)
+	}
+	opts.Date = "2025-12-28"
+	opts.Clock = at("2025-12-30")
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if content := readEntry(t, mem, "2025-12-28"); strings.Contains(content, "Backlog") {
+		t.Errorf("expected no backlog with a one-day lookback:\n%s", content)
+	}
+}
+
+func TestRunTodoProvenance(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	run := func(date string, msgs ...tea.Msg) *domain.JournalEntry {
+		t.Helper()
+		o := opts
+		o.Clock = at(date)
+		o.RunTUI = scriptedTUI(msgs...)
+		if err := Run(o); err != nil {
+			t.Fatalf("Run %s: %v", date, err)
+		}
+		entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, date)))
+		if err != nil {
+			t.Fatalf("parse %s: %v", date, err)
+		}
+		return entry
+	}
+
+	day1 := run("2025-12-22", enter, enter, enter, enter, typeText("renew passport"), enter, enter, enter)
+	if len(day1.Todos) != 1 || day1.Todos[0].ID == "" || day1.Todos[0].Created.Format("2006-01-02") != "2025-12-22" || day1.Todos[0].Carried != 0 {
+		t.Fatalf("day 1 todos = %+v", day1.Todos)
+	}
+	id := day1.Todos[0].ID
+
+	// Carried along without being picked, then picked on the third day.
+	day2 := run("2025-12-23", enter, enter, enter, enter, enter, enter)
+	day3 := run("2025-12-26", enter, enter, enter, enter, tab, space, shiftTab, enter, enter)
+	if len(day2.Backlog) != 1 || day2.Backlog[0].ID != id || day2.Backlog[0].Carried != 1 {
+		t.Fatalf("day 2 backlog = %+v", day2.Backlog)
+	}
+	if len(day3.Todos) != 1 || len(day3.Backlog) != 0 {
+		t.Fatalf("day 3 todos = %+v, backlog = %+v", day3.Todos, day3.Backlog)
+	}
+	got := day3.Todos[0]
+	if got.ID != id || got.Carried != 2 || got.CarriedOn.Format("2006-01-02") != "2025-12-26" || got.Age(day3.Date) != 4 {
+		t.Errorf("day 3 todo = %+v", got)
+	}
+}
+
+func TestRunTodoPriorityAndDue(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	prev := "---\ndate: 2025-12-29\ntemplate: simple\n---\n\n## ✅ Todos – Today\n- [ ] alpha #project/home\n- [ ] beta 🔼\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(prev)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Clock = at("2025-12-30")
+	opts.RunTUI = scriptedTUI(
+		enter, enter, enter, enter,
+		tab, typeText("p"), // alpha: highest priority
+		typeText("j"), typeText("d"), typeText("+3d"), enter, // beta: due in three days
+		typeText("f"), space, // only beta has a due date; pick it
+		shiftTab, enter,
+		enter,
+	)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-30")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entry.Todos) != 1 || entry.Todos[0].Text != "beta" || entry.Todos[0].Priority != domain.PriorityMedium ||
+		entry.Todos[0].Due.Format("2006-01-02") != "2026-01-02" {
+		t.Errorf("todos = %+v", entry.Todos)
+	}
+	if len(entry.Backlog) != 1 || entry.Backlog[0].Priority != domain.PriorityHighest || entry.Backlog[0].Project() != "home" {
+		t.Errorf("backlog = %+v", entry.Backlog)
+	}
+}
+
+func TestRunRecurringTodos(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	config := "obsidian_vault: /vault\njournal_dir: Journal\n" +
+		"recurring_todos:\n" +
+		"  - text: Plan the week\n" +
+		"    days: mon\n" +
+		"  - text: Inbox zero\n" +
+		"    rule: FREQ=DAILY\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/config.yaml"), []byte(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday: both are added to the new entry.
+	monday := opts
+	monday.Clock = at("2025-12-29")
+	monday.RunTUI = scriptedTUI(enter, enter, enter, enter, enter, enter)
+	if err := Run(monday); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-29")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entry.Todos) != 2 || entry.Todos[0].Text != "Plan the week" || entry.Todos[1].Text != "Inbox zero" {
+		t.Errorf("todos = %+v", entry.Todos)
+	}
+
+	// Reopening the entry does not add them again.
+	var todos []domain.Todo
+	monday.RunTUI = func(m tui.Model) (tui.Model, error) {
+		todos = m.Entry.Todos
+		return m, nil
+	}
+	if err := Run(monday); !errors.Is(err, ErrCancelled) {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(todos) != 2 {
+		t.Errorf("reopened todos = %+v", todos)
+	}
+
+	// Tuesday: Monday's open "Inbox zero" comes back in the backlog instead
+	// of twice.
+	tuesday := opts
+	tuesday.Clock = at("2025-12-30")
+	tuesday.RunTUI = scriptedTUI(enter, enter, enter, enter, enter, enter)
+	if err := Run(tuesday); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	entry, err = markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-30")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entry.Todos) != 0 || len(entry.Backlog) != 2 {
+		t.Errorf("todos = %+v, backlog = %+v", entry.Todos, entry.Backlog)
+	}
+}
+
+func TestUpdateTodosStatus(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	md := "---\ndate: 2025-12-29\ntemplate: simple\n---\n\n## ✅ Todos – Today\n" +
+		"- [ ] alpha\n- [ ] beta\n- [ ] gamma\n- [ ] delta\n- [ ] epsilon\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(md)); err != nil {
+		t.Fatal(err)
+	}
+	opts.Clock = at("2025-12-29")
+	opts.Stdin = strings.NewReader("c\np\nx\nd\nn\n")
+	if err := UpdateTodos(opts); err != nil {
+		t.Fatalf("UpdateTodos: %v", err)
+	}
+	entry, err := markdown.ParseMarkdown([]byte(readEntry(t, mem, "2025-12-29")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var got []string
+	for _, todo := range entry.Todos {
+		got = append(got, todo.Text+" "+todo.Status.String())
+	}
+	want := []string{"alpha done", "beta in progress", "gamma cancelled", "delta deferred"}
+	if !slices.Equal(got, want) {
+		t.Errorf("todos = %q, want %q", got, want)
+	}
+	if len(entry.Backlog) != 1 || entry.Backlog[0].Text != "epsilon" {
+		t.Errorf("backlog = %+v", entry.Backlog)
+	}
+
+	// The next day carries over everything but the done and cancelled items.
+	backlog, err := todo.GetBacklog(mem, filepath.FromSlash("/vault/Journal/2025-12-29.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backlog) != 3 || backlog[0].Status != domain.StatusInProgress {
+		t.Errorf("carried over = %+v", backlog)
+	}
+}
+
+func TestListTodos(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	files := map[string]string{
+		"2025-12-29": "---\ndate: 2025-12-29\n---\n\n## ✅ Todos – Today\n- [ ] Renew passport #admin 📅 2025-12-30\n- [x] Book dentist\n",
+		"2025-12-30": "---\ndate: 2025-12-30\n---\n\n## ✅ Todos – Today\n- [/] Write report ⏫\n\n## 🔁 Backlog\n- [ ] Renew passport #admin 📅 2025-12-30\n",
+	}
+	for date, content := range files {
+		if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/"+date+".md"), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts.Clock = at("2025-12-31")
+	list := func(q TodoQuery) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		opts.Stdout = out
+		if err := ListTodos(opts, q); err != nil {
+			t.Fatalf("ListTodos(%+v): %v", q, err)
+		}
+		return out.String()
+	}
+
+	table := list(TodoQuery{})
+	if !strings.Contains(table, "2025-12-30  open         ") || !strings.Contains(table, "2025-12-30 (overdue)") ||
+		!strings.Contains(table, "in progress  high") || strings.Contains(table, "Book dentist") {
+		t.Errorf("table:\n%s", table)
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(list(TodoQuery{Statuses: []string{"all"}, Format: "json"})), &items); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if len(items) != 3 || items[0]["text"] != "Renew passport #admin" || items[0]["overdue"] != true || items[1]["status"] != "done" {
+		t.Errorf("json = %v", items)
+	}
+
+	if got := list(TodoQuery{Tag: "admin", Format: "markdown"}); got != "- [ ] Renew passport #admin ➕ 2025-12-29 📅 2025-12-30\n" {
+		t.Errorf("markdown = %q", got)
+	}
+	if got := list(TodoQuery{Range: "2025-12-29", Statuses: []string{"done"}, Format: "markdown"}); got != "- [x] Book dentist ➕ 2025-12-29\n" {
+		t.Errorf("markdown for 2025-12-29 = %q", got)
+	}
+
+	if err := ListTodos(opts, TodoQuery{Statuses: []string{"finished"}}); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	md := "---\ndate: 2025-12-29\ntemplate: simple\nhighlight: Finished the migration\n---\n\n" +
+		"## 🧠 What did I learn?\n<!-- question: learned -->\nMigrations need a rollback plan.\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(md)); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	opts.Stdout = out
+	if err := Search(opts, "migration", 0); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	want := "2025-12-29 Mon (simple)\n" +
+		"  Highlight: Finished the migration\n" +
+		"  What did I learn?: Migrations need a rollback plan.\n" +
+		"\n1 of 1 matching entries shown.\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+
+	out.Reset()
+	if err := Search(opts, "migration -rollback", 0); err != nil || out.String() != "No matching entries.\n" {
+		t.Errorf("Search = %v, output %q", err, out)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	opts.Clock = at("2025-12-29")
+	md := "---\ndate: 2025-12-29\ntemplate: simple\n---\n\n## ✅ Todos – Today\n- [ ] Plan the migration\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(md)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first query stores the index in the cache directory.
+	if err := Search(opts, "migration", 0); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	files, err := mem.ReadDir(filepath.FromSlash("/cache"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("cache dir = %v, %v", files, err)
+	}
+	path := filepath.Join(filepath.FromSlash("/cache"), files[0].Name())
+
+	// Writing an entry updates the stored index without a query.
+	opts.Stdin = strings.NewReader("c\n")
+	if err := UpdateTodos(opts); err != nil {
+		t.Fatalf("UpdateTodos: %v", err)
+	}
+	x, err := index.Load(mem, path, filepath.FromSlash("/vault/Journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := x.Docs()
+	if len(docs) != 1 || len(docs[0].Entry.Todos) != 1 || !docs[0].Entry.Todos[0].Done() {
+		t.Errorf("stored index after UpdateTodos = %+v", docs)
+	}
+
+	out := &bytes.Buffer{}
+	opts.Stdout = out
+	if err := RebuildIndex(opts); err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	if want := "Indexed 1 files of " + filepath.FromSlash("/vault/Journal") + " in " + path + "\n"; out.String() != want {
+		t.Errorf("RebuildIndex output %q, want %q", out, want)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	opts.Clock = at("2025-12-30")
+	files := map[string]string{
+		"2025-12-28.md": "---\ndate: 2025-12-28\ntemplate: simple\nhighlight: Database migration\n---\n\n" +
+			"## ✅ Todos – Today\n- [ ] Plan the rollback\n",
+		"2025-12-29.md": "---\ndate: 2025-12-29\ntemplate: simple\nmood: calm\n---\n",
+	}
+	for name, content := range files {
+		if err := mem.WriteFile(filepath.Join(filepath.FromSlash("/vault/Journal"), name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts.Stdin = strings.NewReader("c\n")
+
+	var runs []tui.Browser
+	scripts := [][]tea.Msg{
+		{typeText("/"), typeText("mgrtn"), enter, typeText("t")},
+		{typeText("q")},
+	}
+	opts.RunBrowser = func(b tui.Browser) (tui.Browser, error) {
+		if len(runs) == 0 && b.View() == "" {
+			t.Error("empty view")
+		}
+		for _, msg := range scripts[len(runs)] {
+			next, _ := b.Update(msg)
+			b = next.(tui.Browser)
+		}
+		runs = append(runs, b)
+		return b, nil
+	}
+	if err := Browse(opts); err != nil {
+		t.Fatalf("Browse: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("browser ran %d times, want 2", len(runs))
+	}
+
+	// The search found the entry with an abbreviated word and the todo
+	// prompt ran for it; the browser came back on the same entry.
+	if view := runs[0].View(); !strings.Contains(view, "1 of 2 entries") || !strings.Contains(view, "Database migration") {
+		t.Errorf("view after search:\n%s", view)
+	}
+	if !strings.Contains(readEntry(t, mem, "2025-12-28"), "- [x] Plan the rollback") {
+		t.Errorf("todo not completed:\n%s", readEntry(t, mem, "2025-12-28"))
+	}
+	if it, ok := runs[1].Selected(); !ok || it.Entry.Highlight != "Database migration" || !it.Entry.Todos[0].Done() {
+		t.Errorf("second run selected %+v", it.Entry)
+	}
+	if runs[1].SearchInput.Value() != "mgrtn" {
+		t.Errorf("search not kept: %q", runs[1].SearchInput.Value())
+	}
+}
+
+func TestShowCalendar(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	opts.Clock = at("2025-12-30")
+	for date, mood := range map[string]string{"2025-11-30": "tired", "2025-12-01": "calm", "2025-12-29": "calm"} {
+		md := "---\ndate: " + date + "\ntemplate: simple\nmood: " + mood + "\n---\n"
+		if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/"+date+".md"), []byte(md)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got tui.Calendar
+	opts.RunCalendar = func(c tui.Calendar) (tui.Calendar, error) {
+		for _, msg := range []tea.Msg{typeText("["), typeText("["), typeText("]"), typeText("c")} {
+			next, _ := c.Update(msg)
+			c = next.(tui.Calendar)
+		}
+		got = c
+		return c, nil
+	}
+	opts.Date = "2025-12-29"
+	if err := ShowCalendar(opts, false, "mood"); err != nil {
+		t.Fatalf("ShowCalendar: %v", err)
+	}
+	if len(got.Days) != 3 || got.Cursor.Format("2006-01-02") != "2025-11-29" || got.ColorBy != tui.ColorEnergy {
+		t.Errorf("calendar: %d days, cursor %s, colour by %s", len(got.Days), got.Cursor.Format("2006-01-02"), got.ColorBy)
+	}
+	if view := got.View(); !strings.Contains(view, "November 2025  1 of 30 days") || !strings.Contains(view, "Saturday, 29 Nov 2025 · no entry") {
+		t.Errorf("view:\n%s", view)
+	}
+	if err := ShowCalendar(opts, true, "colour"); err == nil {
+		t.Error("ShowCalendar accepted an unknown colouring")
+	}
+
+	// The template screen shows the month of the entry.
+	opts.Date = ""
+	opts.RunTUI = func(m tui.Model) (tui.Model, error) {
+		if view := m.View(); !strings.Contains(view, "December 2025  2 of 31 days") {
+			t.Errorf("template screen:\n%s", view)
+		}
+		return m, nil
+	}
+	if err := Run(opts); err != ErrCancelled {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestPrintStats(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	opts.Clock = at("2025-12-02")
+	files := map[string]string{
+		"2025-11-30": "- [x] Ship\n- [ ] Test\n",
+		"2025-12-01": "- [x] Rest\n",
+		"2025-12-02": "",
+	}
+	for date, todos := range files {
+		md := "---\ndate: " + date + "\ntemplate: simple\n---\n\n## ✅ Todos – Today\n" + todos +
+			"\n## 🧠 What did I learn?\n<!-- question: learned -->\nA thing or two.\n"
+		if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/"+date+".md"), []byte(md)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := &bytes.Buffer{}
+	opts.Stdout = out
+	if err := PrintStats(opts, "", false); err != nil {
+		t.Fatalf("PrintStats: %v", err)
+	}
+	want := "Journal:          " + filepath.FromSlash("/vault/Journal") + "\n" +
+		"Range:            2025-11-30 to 2025-12-02 (3 days)\n" +
+		"Entries:          3 (7.0 per week, 30.7 per month)\n" +
+		"Current streak:   3 days\n" +
+		"Longest streak:   3 days, to Tuesday, 02 Dec 2025\n" +
+		"Average words:    4 per entry\n" +
+		"Todos done:       67% (2 of 3)\n" +
+		"\n" +
+		"MONTH    ENTRIES  WORDS  TODOS DONE\n" +
+		"2025-11  1/1      4      50% (1 of 2)\n" +
+		"2025-12  2/2      8      100% (1 of 1)\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+
+	out.Reset()
+	if err := PrintStats(opts, "today", false); err != nil {
+		t.Fatalf("PrintStats today: %v", err)
+	}
+	if !strings.Contains(out.String(), "Range:            2025-12-02 to 2025-12-02 (1 day)\n") {
+		t.Errorf("one day range:\n%s", out)
+	}
+
+	out.Reset()
+	if err := PrintStats(opts, "2025-12", true); err != nil {
+		t.Fatalf("PrintStats --json: %v", err)
+	}
+	var got struct {
+		Start         string `json:"start"`
+		Entries       int    `json:"entries"`
+		CurrentStreak int    `json:"current_streak"`
+		Months        []struct {
+			Month string `json:"month"`
+		} `json:"months"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("JSON: %v\n%s", err, out)
+	}
+	if got.Start != "2025-12-01" || got.Entries != 2 || got.CurrentStreak != 2 || len(got.Months) != 1 || got.Months[0].Month != "2025-12" {
+		t.Errorf("JSON = %+v", got)
+	}
+}
+
+func TestRunRejectsFutureDate(t *testing.T) {
+	opts, _ := newTestEnv(t)
+	opts.Clock = at("2025-12-30")
+	opts.Date = "tomorrow"
+	opts.RunTUI = func(tui.Model) (tui.Model, error) {
+		t.Fatal("TUI should not start for a future date")
+		return tui.Model{}, nil
+	}
+	if err := Run(opts); err == nil || !strings.Contains(err.Error(), "future") {
+		t.Fatalf("expected future date error, got %v", err)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	opts.Clock = at("2025-12-30")
+	opts.RunTUI = scriptedTUI(enter) // quit on the mood step
+	if err := Run(opts); err != ErrCancelled {
+		t.Fatalf("expected ErrCancelled, got %v", err)
+	}
+	if mem.Exists(filepath.FromSlash("/vault/Journal/2025-12-30.md")) {
+		t.Error("cancelled run should not write a file")
+	}
+}
+
+func TestEditRequiresExistingEntry(t *testing.T) {
+	opts, _ := newTestEnv(t)
+	opts.Clock = at("2025-12-30")
+	if err := Edit(opts); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestShow(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	content := "---\ndate: 2025-12-29\n---\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	opts.Stdout = &out
+	opts.Clock = at("2025-12-30")
+	opts.Date = "yesterday"
+	if err := Show(opts); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+	if out.String() != content {
+		t.Errorf("Show output = %q, want %q", out.String(), content)
+	}
+}
+
+func TestMigrateIDs(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	legacy := "---\ndate: 2025-12-29\ntemplate: simple\n---\n\n## 🧠 What did I learn?\nMaps are unordered.\n"
+	if err := mem.WriteFile(filepath.FromSlash("/vault/Journal/2025-12-29.md"), []byte(legacy)); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	opts.Stdout = &out
+
+	if err := MigrateIDs(opts, true); err != nil {
+		t.Fatalf("MigrateIDs dry run: %v", err)
+	}
+	if got := readEntry(t, mem, "2025-12-29"); got != legacy {
+		t.Fatalf("dry run wrote the entry:\n%s", got)
+	}
+
+	if err := MigrateIDs(opts, false); err != nil {
+		t.Fatalf("MigrateIDs: %v", err)
+	}
+	want := "---\ndate: 2025-12-29\ntemplate: simple\n---\n\n## 🧠 What did I learn?\n<!-- question: learned -->\nMaps are unordered.\n"
+	if got := readEntry(t, mem, "2025-12-29"); got != want {
+		t.Fatalf("migrated entry = %q, want %q", got, want)
+	}
+	if !strings.Contains(out.String(), "Updated 1 entries") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	// Once the entry has an ID, retitling the question keeps the answer.
+	retitled := "name: simple\nquestions:\n  - id: learned\n    title: What stuck with me?\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/templates/simple.yaml"), []byte(retitled)); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := MigrateIDs(opts, false); err != nil {
+		t.Fatalf("MigrateIDs after retitle: %v", err)
+	}
+	if got := readEntry(t, mem, "2025-12-29"); !strings.Contains(got, "## 🧠 What stuck with me?\n<!-- question: learned -->\nMaps are unordered.\n") {
+		t.Errorf("retitled entry = %q", got)
+	}
+}
+
+func TestRunNestedLayout(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	config := "obsidian_vault: /vault\njournal_dir: Journal\npath_pattern: YYYY/MM/YYYY-MM-DD ddd\n"
+	prev := "---\ndate: 2025-11-30\ntemplate: simple\n---\n\n## ✅ Todos – Today\n- [ ] from november\n"
+	for path, content := range map[string]string{
+		"/cfg/config.yaml":                         config,
+		"/vault/Journal/2025/11/2025-11-30 Sun.md": prev,
+	} {
+		if err := mem.WriteFile(filepath.FromSlash(path), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts.Clock = at("2025-12-01")
+	opts.RunTUI = scriptedTUI(enter, enter, enter, enter, enter, enter)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	data, err := mem.ReadFile(filepath.FromSlash("/vault/Journal/2025/12/2025-12-01 Mon.md"))
+	if err != nil {
+		t.Fatalf("entry not written to the nested path: %v", err)
+	}
+	entry, err := markdown.ParseMarkdown(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entry.Backlog) != 1 || entry.Backlog[0].Text != "from november" {
+		t.Errorf("expected backlog from 2025-11-30, got %v", entry.Backlog)
+	}
+
+	var out bytes.Buffer
+	opts.Stdout = &out
+	opts.Date = "yesterday"
+	if err := Show(opts); err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+	if !strings.Contains(out.String(), "from november") {
+		t.Errorf("Show printed %q", out.String())
+	}
+}
+
+func TestMigrateLayout(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	for path, content := range map[string]string{
+		"/cfg/config.yaml":                     "obsidian_vault: /vault\njournal_dir: Journal\npath_pattern: YYYY/MM/YYYY-MM-DD\n",
+		"/vault/Journal/2025-12-29.md":         "monday",
+		"/vault/Journal/2025-12-30.md":         "tuesday",
+		"/vault/Journal/2025-W52.md":           "review",
+		"/vault/Journal/2025/12/2025-12-30.md": "already there",
+	} {
+		if err := mem.WriteFile(filepath.FromSlash(path), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	opts.Stdout = &out
+	if err := MigrateLayout(opts, "", true); err != nil {
+		t.Fatalf("MigrateLayout dry run: %v", err)
+	}
+	if mem.Exists(filepath.FromSlash("/vault/Journal/2025/12/2025-12-29.md")) {
+		t.Error("dry run moved a file")
+	}
+	if !strings.Contains(out.String(), "Would move 1 entries") {
+		t.Errorf("dry run output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := MigrateLayout(opts, "", false); err != nil {
+		t.Fatalf("MigrateLayout: %v", err)
+	}
+	for path, want := range map[string]string{
+		"/vault/Journal/2025/12/2025-12-29.md": "monday",
+		"/vault/Journal/2025/12/2025-12-30.md": "already there",
+		"/vault/Journal/2025-12-30.md":         "tuesday",
+		"/vault/Journal/2025-W52.md":           "review",
+	} {
+		if got, err := mem.ReadFile(filepath.FromSlash(path)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if mem.Exists(filepath.FromSlash("/vault/Journal/2025-12-29.md")) {
+		t.Error("moved entry still at its old path")
+	}
+	if !strings.Contains(out.String(), "skip 2025-12-30.md") {
+		t.Errorf("expected the conflict to be reported:\n%s", out.String())
+	}
+
+	// Back to the flat layout; the emptied folders are removed.
+	if err := mem.Remove(filepath.FromSlash("/vault/Journal/2025-12-30.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/config.yaml"), []byte("obsidian_vault: /vault\njournal_dir: Journal\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateLayout(opts, "YYYY/MM/YYYY-MM-DD", false); err != nil {
+		t.Fatalf("MigrateLayout back: %v", err)
+	}
+	if !mem.Exists(filepath.FromSlash("/vault/Journal/2025-12-30.md")) || mem.Exists(filepath.FromSlash("/vault/Journal/2025")) {
+		t.Errorf("expected a flat journal again:\n%s", out.String())
+	}
+}
+
+func TestRunTypedQuestions(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	if err := mem.Remove(filepath.FromSlash("/cfg/templates/simple.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	typed := "name: typed\n" +
+		"questions:\n" +
+		"  - {id: focus, title: Focus, type: scale, min: 1, max: 5, labels: {1: scattered, 5: deep}}\n" +
+		"  - {id: exercised, title: Exercised, type: boolean}\n" +
+		"  - {id: sleep, title: Sleep, type: number, unit: h, max: 24}\n" +
+		"  - {id: weather, title: Weather, type: choice, options: [sunny, rainy]}\n" +
+		"  - {id: did, title: Did, type: multichoice, options: [code, read, walk]}\n" +
+		"  - {id: wins, title: Wins, type: list}\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/templates/typed.yaml"), []byte(typed)); err != nil {
+		t.Fatal(err)
+	}
+
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	opts.Clock = at("2025-12-30")
+	opts.RunTUI = scriptedTUI(
+		enter, enter, enter, enter, // template, mood, energy, highlight
+		enter,                               // no todos
+		typeText("9"), typeText("4"), enter, // 9 is out of range and ignored
+		typeText("y"), enter,
+		typeText("25"), enter, // rejected: above max
+		backspace, backspace, typeText("7.5"), enter,
+		down, down, enter,
+		space, down, down, space, enter,
+		typeText("shipped"), enter, typeText("slept"), enter, enter,
+	)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got := readEntry(t, mem, "2025-12-30")
+	for _, want := range []string{
+		"metrics:\n    exercised: 1\n    focus: 4\n    sleep: 7.5\n",
+		"## 🧠 Focus\n<!-- question: focus -->\n4/5\n",
+		"## 🧠 Exercised\n<!-- question: exercised -->\nYes\n",
+		"## 🧠 Sleep\n<!-- question: sleep -->\n7.5 h\n",
+		"## 🧠 Weather\n<!-- question: weather -->\nrainy\n",
+		"## 🧠 Did\n<!-- question: did -->\n- code\n- walk\n",
+		"## 🧠 Wins\n<!-- question: wins -->\n- shipped\n- slept\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("entry missing %q\n--- got ---\n%s", want, got)
+		}
+	}
+
+	entry, err := markdown.ParseMarkdown([]byte(got))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if entry.Metrics["sleep"] != 7.5 || entry.Metrics["focus"] != 4 {
+		t.Errorf("metrics not parsed back: %v", entry.Metrics)
+	}
+}
+
+func TestRunRequiredAndConditionalQuestions(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	if err := mem.Remove(filepath.FromSlash("/cfg/templates/simple.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "name: checkin\n" +
+		"questions:\n" +
+		"  - {id: energy, title: Energy, type: scale, max: 5, required: true}\n" +
+		"  - {id: drained, title: 'What drained you?', show_if: energy <= 3}\n" +
+		"  - {id: note, title: Note, default: nothing much}\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/templates/checkin.yaml"), []byte(tmpl)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Low energy: the follow-up is asked. Enter on the blank required
+	// question does not move on.
+	day1 := opts
+	day1.Clock = at("2025-12-29")
+	day1.RunTUI = scriptedTUI(
+		enter, enter, enter, enter, enter,
+		enter, typeText("2"), enter,
+		typeText("meetings"), enter,
+		enter, // keep the default note
+	)
+	if err := Run(day1); err != nil {
+		t.Fatalf("Run day 1: %v", err)
+	}
+	got := readEntry(t, mem, "2025-12-29")
+	for _, want := range []string{"2/5", "## 🧠 What drained you?\n<!-- question: drained -->\nmeetings", "nothing much"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("day 1 missing %q\n--- got ---\n%s", want, got)
+		}
+	}
+
+	// High energy: the follow-up is skipped and left out of the entry.
+	day2 := opts
+	day2.Clock = at("2025-12-30")
+	day2.RunTUI = scriptedTUI(
+		enter, enter, enter, enter, enter,
+		typeText("5"), enter,
+		typeText("fine"), enter,
+	)
+	if err := Run(day2); err != nil {
+		t.Fatalf("Run day 2: %v", err)
+	}
+	got = readEntry(t, mem, "2025-12-30")
+	if strings.Contains(got, "drained") || !strings.Contains(got, "5/5") || !strings.Contains(got, "nothing muchfine") {
+		t.Errorf("unexpected day 2 entry:\n%s", got)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	var out bytes.Buffer
+	opts.Stdout = &out
+	if err := ValidateTemplates(opts, nil, false); err != nil {
+		t.Fatalf("ValidateTemplates: %v\n%s", err, out.String())
+	}
+
+	bad := "name: bad\nquestions:\n  - id: q\n    title: Q\n    type: slider\n"
+	if err := mem.WriteFile(filepath.FromSlash("/cfg/templates/bad.yaml"), []byte(bad)); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := ValidateTemplates(opts, nil, false); err == nil {
+		t.Fatalf("expected an error for bad.yaml")
+	}
+	if !strings.Contains(out.String(), `bad.yaml:3:5: error: question "Q": unknown type "slider"`) {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestRunSelectsTemplateByRule(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	files := map[string]string{
+		"/cfg/config.yaml": "obsidian_vault: /vault\njournal_dir: Journal\n" +
+			"default_template: simple\n" +
+			"skip_template_picker: true\n" +
+			"template_rules:\n  - days: weekends\n    template: weekend\n",
+		"/cfg/templates/weekend.yaml": "name: weekend\nquestions:\n  - id: rest\n    title: How did I rest?\n",
+	}
+	for path, content := range files {
+		if err := mem.WriteFile(filepath.FromSlash(path), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Saturday: the picker is skipped and the weekend template is used.
+	opts.Clock = at("2025-12-27")
+	opts.RunTUI = scriptedTUI(
+		typeText("calm"), enter,
+		enter, enter, enter, // energy, highlight, no todos
+		typeText("long walk"), enter,
+	)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := readEntry(t, mem, "2025-12-27"); !strings.Contains(got, "template: weekend") || !strings.Contains(got, "long walk") {
+		t.Errorf("expected the weekend template:\n%s", got)
+	}
+
+	// Sunday: ctrl+t goes back to the list, where the rule's template is
+	// preselected, and another one can be picked instead.
+	opts.Clock = at("2025-12-28")
+	opts.RunTUI = scriptedTUI(
+		tea.KeyMsg{Type: tea.KeyCtrlT},
+		tea.KeyMsg{Type: tea.KeyUp}, enter, // simple is listed first
+		typeText("calm"), enter,
+		enter, enter, enter,
+		typeText("maps"), enter,
+	)
+	if err := Run(opts); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := readEntry(t, mem, "2025-12-28"); !strings.Contains(got, "template: simple") || !strings.Contains(got, "maps") {
+		t.Errorf("expected the simple template after overriding:\n%s", got)
+	}
+}
+
+func TestReview(t *testing.T) {
+	opts, mem := newTestEnv(t)
+	files := map[string]string{
+		"/cfg/templates/weekly.yaml": "name: weekly\nperiod: week\nquestions:\n  - id: went-well\n    title: What went well?\n",
+		"/vault/Journal/2025-12-22.md": "---\ndate: 2025-12-22\ntemplate: simple\nmood: \"6\"\nenergy: low\nhighlight: shipped the parser\n---\n\n" +
+			"## ✅ Todos – Today\n- [x] write tests\n- [ ] ship it\n",
+		"/vault/Journal/2025-12-24.md": "---\ndate: 2025-12-24\ntemplate: simple\nmood: \"8\"\nenergy: low\nhighlight: \"\"\n---\n\n" +
+			"## ✅ Todos – Today\n- [x] ship it\n- [ ] write docs\n",
+	}
+	for path, content := range files {
+		if err := mem.WriteFile(filepath.FromSlash(path), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts.Clock = at("2025-12-30")
+	opts.Date = "last week"
+	opts.RunTUI = scriptedTUI(
+		enter, // the only weekly template
+		enter, // past the summary
+		typeText("rested"), enter,
+	)
+	if err := Review(opts, domain.PeriodDay); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	data, err := mem.ReadFile(filepath.FromSlash("/vault/Journal/2025-W52.md"))
+	if err != nil {
+		t.Fatalf("read review: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"date: \"2025-12-22\"\nperiod: week\ntemplate: weekly\n",
+		"# Weekly Review – 2025-W52",
+		"## 📊 Period Summary\n- Entries: 2 of 7 days\n- Mood: Mon 6 → Wed 8 (average 7)\n- Energy: Mon low → Wed low\n- Todos: 2 completed, 1 carried over\n",
+		"**Highlights**\n- Mon 22 Dec: shipped the parser\n",
+		"**Carried over**\n- write docs\n",
+		"## 🧠 What went well?\n<!-- question: went-well -->\nrested\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("review is missing %q:\n%s", want, got)
+		}
+	}
+
+	// Reopening the review goes straight to the summary and keeps answers.
+	opts.Date = "2025-12-25"
+	opts.RunTUI = scriptedTUI(enter, enter)
+	if err := Review(opts, domain.PeriodWeek); err != nil {
+		t.Fatalf("Review again: %v", err)
+	}
+	again, _ := mem.ReadFile(filepath.FromSlash("/vault/Journal/2025-W52.md"))
+	if string(again) != got {
+		t.Errorf("reopening the review changed it:\n%s", again)
+	}
+
+	opts.Date = "2026-01"
+	if err := Review(opts, domain.PeriodDay); err == nil {
+		t.Errorf("expected an error for a future month")
+	}
+}
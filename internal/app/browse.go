@@ -0,0 +1,68 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"journal-cli/internal/tui"
+)
+
+// Browse opens the browse mode on the journal's daily entries. Picking an
+// entry opens it in the journaling TUI, or prompts for its todos, and then
+// returns to the browser on the same entry with the same search.
+func Browse(opts Options) error {
+	opts = opts.withDefaults()
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	journalDir := resolveJournalDir(cfg)
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+
+	var selected, query string
+	for {
+		x, err := openIndex(opts, journalDir)
+		if err != nil {
+			return err
+		}
+		var items []tui.BrowseItem
+		for _, d := range x.Daily(l) {
+			if d.Entry != nil {
+				items = append(items, tui.BrowseItem{Date: d.Date, Path: d.Path, Entry: d.Entry})
+			}
+		}
+		if len(items) == 0 {
+			fmt.Fprintf(opts.Stdout, "No entries found in %s\n", journalDir)
+			return nil
+		}
+		slices.Reverse(items)
+
+		b := tui.NewBrowser(items)
+		b.Search(query)
+		b.Select(selected)
+		if b, err = opts.RunBrowser(b); err != nil {
+			return err
+		}
+		it, ok := b.Selected()
+		if !b.Done || b.Action == tui.BrowseQuit || !ok {
+			return nil
+		}
+		selected, query = it.Path, b.SearchInput.Value()
+
+		entryOpts := opts
+		entryOpts.Date = it.Date.Format("2006-01-02")
+		switch b.Action {
+		case tui.BrowseEdit:
+			err = Edit(entryOpts)
+		case tui.BrowseTodos:
+			err = UpdateTodos(entryOpts)
+		}
+		if err != nil && !errors.Is(err, ErrCancelled) {
+			return err
+		}
+	}
+}
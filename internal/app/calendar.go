@@ -0,0 +1,45 @@
+package app
+
+import (
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/stats"
+	"journal-cli/internal/tui"
+)
+
+// ShowCalendar opens the calendar of the days with an entry on the month,
+// or with year set the year, of the date selected by opts. colorBy names
+// what days are coloured by, see tui.ParseCalendarColor; empty colours
+// every entry alike.
+func ShowCalendar(opts Options, year bool, colorBy string) error {
+	opts = opts.withDefaults()
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+	date, err := resolveDate(opts)
+	if err != nil {
+		return err
+	}
+	c := tui.NewCalendar(nil, opts.Clock.Now())
+	if date.Before(c.Today) {
+		c.Cursor = dateexpr.Day(date)
+	}
+	c.Year = year
+	if colorBy != "" {
+		if c.ColorBy, err = tui.ParseCalendarColor(colorBy); err != nil {
+			return err
+		}
+	}
+
+	x, err := openIndex(opts, resolveJournalDir(cfg))
+	if err != nil {
+		return err
+	}
+	c.Days = stats.NewCalendar(x.Daily(l))
+	_, err = opts.RunCalendar(c)
+	return err
+}
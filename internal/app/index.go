@@ -0,0 +1,96 @@
+package app
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"journal-cli/internal/index"
+)
+
+// indexFile returns where the index of journalDir is kept: a file per
+// journal directory in the cache directory.
+func indexFile(opts Options, journalDir string) (string, error) {
+	dir := opts.CacheDir
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cache, "journal-cli")
+	}
+	abs, err := filepath.Abs(journalDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(abs))
+	return filepath.Join(dir, "index-"+hex.EncodeToString(sum[:6])+".gob"), nil
+}
+
+// openIndex returns the index of journalDir, brought up to date with the
+// files on disk. The index is only a cache: when it cannot be read or
+// saved the entries are indexed again in memory.
+func openIndex(opts Options, journalDir string) (*index.Index, error) {
+	path, pathErr := indexFile(opts, journalDir)
+	x := index.New(journalDir)
+	if pathErr == nil {
+		if loaded, err := index.Load(opts.FS, path, journalDir); err == nil {
+			x = loaded
+		}
+	}
+	if err := x.Update(opts.FS); err != nil {
+		return nil, fmt.Errorf("index journal: %w", err)
+	}
+	if pathErr == nil && x.Changed() {
+		_ = saveIndex(opts, x, path)
+	}
+	return x, nil
+}
+
+// updateIndex refreshes the index of journalDir after file was written,
+// so the next query does not have to read it again. Nothing happens when
+// there is no index yet, and failures are left for the next query to fix.
+func updateIndex(opts Options, journalDir, file string) {
+	path, err := indexFile(opts, journalDir)
+	if err != nil || !opts.FS.Exists(path) {
+		return
+	}
+	x, err := index.Load(opts.FS, path, journalDir)
+	if err != nil || x.Refresh(opts.FS, file) != nil {
+		return
+	}
+	_ = saveIndex(opts, x, path)
+}
+
+func saveIndex(opts Options, x *index.Index, path string) error {
+	if err := opts.FS.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return x.Save(opts.FS, path)
+}
+
+// RebuildIndex indexes every entry in the journal again, replacing the
+// stored index.
+func RebuildIndex(opts Options) error {
+	opts = opts.withDefaults()
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	journalDir := resolveJournalDir(cfg)
+	path, err := indexFile(opts, journalDir)
+	if err != nil {
+		return fmt.Errorf("locate index: %w", err)
+	}
+	x := index.New(journalDir)
+	if err := x.Update(opts.FS); err != nil {
+		return fmt.Errorf("index journal: %w", err)
+	}
+	if err := saveIndex(opts, x, path); err != nil {
+		return fmt.Errorf("save index: %w", err)
+	}
+	fmt.Fprintf(opts.Stdout, "Indexed %d files of %s in %s\n", len(x.Docs()), journalDir, path)
+	return nil
+}
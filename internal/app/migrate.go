@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"journal-cli/internal/layout"
+	"journal-cli/internal/markdown"
+	"journal-cli/internal/template"
+)
+
+// MigrateIDs rewrites every entry in the journal so that each answer
+// records the ID of the template question it belongs to. Answers are
+// matched to questions by title; entries are only written when something
+// changed. With dryRun set the files that would change are listed but not
+// written.
+func MigrateIDs(opts Options, dryRun bool) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	templates, _, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]template.Template, len(templates))
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+
+	journalDir := resolveJournalDir(cfg)
+	if !opts.FS.Exists(journalDir) {
+		fmt.Fprintf(w, "No entries found in %s\n", journalDir)
+		return nil
+	}
+	var changed, skipped int
+	err = layout.Walk(opts.FS, journalDir, func(name string) error {
+		path := filepath.Join(journalDir, name)
+		data, err := opts.FS.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		entry, err := markdown.ParseMarkdown(data)
+		if err != nil {
+			fmt.Fprintf(w, "skip %s: %v\n", name, err)
+			skipped++
+			return nil
+		}
+		tmpl, ok := byName[entry.Template]
+		if !ok {
+			if len(entry.Answers) > 0 {
+				fmt.Fprintf(w, "skip %s: template %q not found\n", name, entry.Template)
+				skipped++
+			}
+			return nil
+		}
+
+		keyAnswers(entry, tmpl)
+	answers:
+		for _, a := range entry.Answers {
+			for _, q := range tmpl.Questions {
+				if q.Key() == a.ID {
+					if q.ID == "" {
+						fmt.Fprintf(w, "%s: question %q in %q has no id\n", name, q.Title, tmpl.Name)
+					}
+					continue answers
+				}
+			}
+			fmt.Fprintf(w, "%s: no question in %q matches %q\n", name, tmpl.Name, a.Question)
+		}
+
+		content, err := markdown.GenerateMarkdown(entry)
+		if err != nil {
+			return fmt.Errorf("generate markdown for %s: %w", name, err)
+		}
+		if bytes.Equal(content, data) {
+			return nil
+		}
+		changed++
+		if dryRun {
+			fmt.Fprintf(w, "would update %s\n", name)
+			return nil
+		}
+		if err := opts.FS.WriteFile(path, content); err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		fmt.Fprintf(w, "updated %s\n", name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+	fmt.Fprintf(w, "%s %d entries (%d skipped).\n", verb, changed, skipped)
+	return nil
+}
+
+// MigrateLayout moves the daily entries of the journal from the layout of
+// the path pattern from (layout.Default when empty) to the one set by
+// path_pattern in the config. Existing files are never overwritten, and
+// folders left empty by the moves are removed. With dryRun set the moves
+// are listed but not made.
+func MigrateLayout(opts Options, from string, dryRun bool) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	to, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+	var old layout.Layout
+	if from != "" {
+		if old, err = layout.Parse(from); err != nil {
+			return err
+		}
+	}
+
+	journalDir := resolveJournalDir(cfg)
+	entries, err := old.Entries(opts.FS, journalDir)
+	if err != nil {
+		return fmt.Errorf("read journal dir: %w", err)
+	}
+	rel := func(path string) string {
+		if r, err := filepath.Rel(journalDir, path); err == nil {
+			return r
+		}
+		return path
+	}
+
+	var moved, skipped int
+	for _, e := range entries {
+		target := to.Path(journalDir, e.Date)
+		if target == e.Path {
+			continue
+		}
+		if opts.FS.Exists(target) {
+			fmt.Fprintf(w, "skip %s: %s already exists\n", rel(e.Path), rel(target))
+			skipped++
+			continue
+		}
+		moved++
+		if dryRun {
+			fmt.Fprintf(w, "would move %s -> %s\n", rel(e.Path), rel(target))
+			continue
+		}
+		if err := opts.FS.Rename(e.Path, target); err != nil {
+			return fmt.Errorf("move %s: %w", rel(e.Path), err)
+		}
+		removeEmptyDirs(opts, journalDir, filepath.Dir(e.Path))
+		fmt.Fprintf(w, "moved %s -> %s\n", rel(e.Path), rel(target))
+	}
+
+	verb := "Moved"
+	if dryRun {
+		verb = "Would move"
+	}
+	fmt.Fprintf(w, "%s %d entries to %s (%d skipped).\n", verb, moved, to, skipped)
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents up to, but not including,
+// root while they are empty.
+func removeEmptyDirs(opts Options, root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		files, err := opts.FS.ReadDir(dir)
+		if err != nil || len(files) > 0 || opts.FS.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"journal-cli/internal/clock"
+	"journal-cli/internal/config"
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/template"
+	"journal-cli/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options carries the global command-line settings shared by every command
+// and the dependencies the commands run against.
+type Options struct {
+	ConfigPath string // Path to config.yaml; empty = OS default location
+	Vault      string // Overrides obsidian_vault from the config file
+	Date       string // Target date expression (see dateexpr.Parse); empty = today
+	CacheDir   string // Where the search index is kept; empty = OS cache directory
+
+	// Dependencies. Zero values select the real implementations, so
+	// command-line callers only need the fields above; tests inject fakes.
+	Clock  clock.Clock
+	FS     fs.FS
+	Stdin  io.Reader
+	Stdout io.Writer
+	// RunTUI runs the journaling TUI until it exits and returns the final
+	// model. Tests substitute a driver that feeds scripted key presses.
+	RunTUI func(tui.Model) (tui.Model, error)
+	// RunBrowser runs the browse mode until it exits, like RunTUI.
+	RunBrowser func(tui.Browser) (tui.Browser, error)
+	// RunCalendar runs the calendar until it exits, like RunTUI.
+	RunCalendar func(tui.Calendar) (tui.Calendar, error)
+}
+
+// withDefaults fills in the real implementation of every unset dependency.
+func (o Options) withDefaults() Options {
+	if o.Clock == nil {
+		o.Clock = clock.System{}
+	}
+	if o.FS == nil {
+		o.FS = fs.OS{}
+	}
+	if o.Stdin == nil {
+		o.Stdin = os.Stdin
+	}
+	if o.Stdout == nil {
+		o.Stdout = os.Stdout
+	}
+	if o.RunTUI == nil {
+		o.RunTUI = runProgram
+	}
+	if o.RunBrowser == nil {
+		o.RunBrowser = runBrowser
+	}
+	if o.RunCalendar == nil {
+		o.RunCalendar = runCalendar
+	}
+	return o
+}
+
+// runProgram runs the Bubble Tea program on the real terminal.
+func runProgram(model tui.Model) (tui.Model, error) {
+	finalModel, err := tea.NewProgram(model).Run()
+	if err != nil {
+		return tui.Model{}, fmt.Errorf("run TUI: %w", err)
+	}
+
+	m, ok := finalModel.(tui.Model)
+	if !ok {
+		return tui.Model{}, fmt.Errorf("unexpected model type %T", finalModel)
+	}
+	return m, nil
+}
+
+// runBrowser runs the browse mode full screen on the real terminal.
+func runBrowser(b tui.Browser) (tui.Browser, error) {
+	finalModel, err := tea.NewProgram(b, tea.WithAltScreen()).Run()
+	if err != nil {
+		return tui.Browser{}, fmt.Errorf("run TUI: %w", err)
+	}
+
+	m, ok := finalModel.(tui.Browser)
+	if !ok {
+		return tui.Browser{}, fmt.Errorf("unexpected model type %T", finalModel)
+	}
+	return m, nil
+}
+
+// runCalendar runs the calendar on the real terminal.
+func runCalendar(c tui.Calendar) (tui.Calendar, error) {
+	finalModel, err := tea.NewProgram(c).Run()
+	if err != nil {
+		return tui.Calendar{}, fmt.Errorf("run TUI: %w", err)
+	}
+
+	m, ok := finalModel.(tui.Calendar)
+	if !ok {
+		return tui.Calendar{}, fmt.Errorf("unexpected model type %T", finalModel)
+	}
+	return m, nil
+}
+
+// loadConfig reads the config file selected by opts and applies overrides.
+func loadConfig(opts Options) (*config.Config, error) {
+	configPath := opts.ConfigPath
+	if configPath == "" {
+		dir, err := config.Dir()
+		if err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		configPath = filepath.Join(dir, "config.yaml")
+	}
+	cfg, err := config.LoadConfigFrom(opts.FS, configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if opts.Vault != "" {
+		cfg.ObsidianVault = opts.Vault
+	}
+	return cfg, nil
+}
+
+// loadTemplates loads templates from the templates folder next to the
+// selected config file, along with the problems found in the files.
+func loadTemplates(opts Options) ([]template.Template, []template.Diagnostic, error) {
+	templates, diags, err := template.Load(opts.FS, templatesDir(opts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("load templates: %w", err)
+	}
+	return templates, diags, nil
+}
+
+// templatesDir returns the directory templates are loaded from.
+func templatesDir(opts Options) string {
+	if opts.ConfigPath != "" {
+		return filepath.Join(filepath.Dir(opts.ConfigPath), "templates")
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "templates"
+	}
+	return filepath.Join(dir, "templates")
+}
+
+// resolveJournalDir determines where daily entries are stored.
+func resolveJournalDir(cfg *config.Config) string {
+	journalDir := cfg.JournalDir
+	if cfg.ObsidianVault != "" {
+		journalDir = filepath.Join(cfg.ObsidianVault, cfg.JournalDir)
+	} else {
+		// Without a vault we can't really guess where the user keeps notes,
+		// so fall back to a folder under the home directory.
+		if journalDir == filepath.Join("Journal", "Daily") { // Default value from config.go
+			home, _ := os.UserHomeDir()
+			journalDir = filepath.Join(home, "Documents", "Journal", "Daily")
+		}
+	}
+	return journalDir
+}
+
+// resolveDate parses the date selected by opts; empty means today.
+func resolveDate(opts Options) (time.Time, error) {
+	return dateexpr.Parse(opts.Date, opts.Clock.Now())
+}
+
+// periodPath returns the path of the review for the period p containing
+// date: YYYY-Www.md, YYYY-MM.md or YYYY.md directly in the journal folder.
+// Daily entries follow the configured layout instead.
+func periodPath(journalDir string, p domain.Period, date time.Time) string {
+	return filepath.Join(journalDir, p.Name(date)+".md")
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/markdown"
+	"journal-cli/internal/review"
+	"journal-cli/internal/stats"
+	"journal-cli/internal/template"
+	"journal-cli/internal/tui"
+)
+
+// Review opens the review entry for the week, month or year containing the
+// date selected by opts (today by default). The TUI shows a summary of the
+// period's daily entries, which is also written to the entry, before the
+// questions of a template for that period. Existing reviews are edited.
+// With period PeriodDay the period is the one the date names, such as
+// 2025-12 or "last week", and a week otherwise.
+func Review(opts Options, period domain.Period) error {
+	opts = opts.withDefaults()
+	fsys, out := opts.FS, opts.Stdout
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	now := opts.Clock.Now()
+	span, err := dateexpr.ParseRange(opts.Date, now)
+	if err != nil {
+		return err
+	}
+	date := dateexpr.Day(now)
+	if !span.IsZero() {
+		date = span.Start
+	}
+	if period == domain.PeriodDay {
+		period = domain.PeriodWeek
+		for _, p := range domain.Periods {
+			if r := p.Range(span.Start); r.Start.Equal(span.Start) && r.End.Equal(span.End) {
+				period = p
+			}
+		}
+	}
+	start := period.Start(date)
+	if start.After(dateexpr.Day(now)) {
+		return fmt.Errorf("cannot review a future %s (%s)", period, period.Name(start))
+	}
+
+	// Review templates are those with a matching period. Users whose
+	// templates folder predates reviews get the bundled ones.
+	all, diags, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+	templates := template.ForPeriod(all, period)
+	if len(templates) == 0 {
+		defaults, err := template.Defaults()
+		if err != nil {
+			return fmt.Errorf("load default templates: %w", err)
+		}
+		templates = template.ForPeriod(defaults, period)
+	}
+	if len(templates) == 0 {
+		return fmt.Errorf("no review templates with \"period: %s\" in %s", period, templatesDir(opts))
+	}
+
+	journalDir := resolveJournalDir(cfg)
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(journalDir); err != nil {
+		return fmt.Errorf("ensure journal directory: %w", err)
+	}
+	file := periodPath(journalDir, period, start)
+
+	entry := domain.NewJournalEntry(start, "")
+	if fsys.Exists(file) {
+		data, err := fsys.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		if entry, err = markdown.ParseMarkdown(data); err != nil {
+			return fmt.Errorf("parse %s: %w", file, err)
+		}
+	}
+	entry.Period = period
+
+	// The summary is rebuilt every time, so it follows edits to the
+	// daily entries.
+	summary, err := review.Summarize(fsys, journalDir, l, period, start)
+	if err != nil {
+		return fmt.Errorf("summarize %s: %w", period.Name(start), err)
+	}
+	entry.Summary = summary.Markdown()
+
+	var s stats.Stats
+	if x, err := openIndex(opts, journalDir); err != nil {
+		fmt.Fprintf(out, "Warning: could not calculate stats: %v\n", err)
+	} else {
+		s = stats.GetStats(x.Daily(l), opts.Clock.Now())
+	}
+
+	model := tui.NewModel(cfg, templates, entry, s)
+	model.Summary = entry.Summary
+	for _, d := range diags {
+		model.TemplateWarnings = append(model.TemplateWarnings, d.Short())
+	}
+	if i := slices.IndexFunc(templates, func(t template.Template) bool { return t.Name == entry.Template }); i >= 0 {
+		keyAnswers(entry, templates[i])
+		model.SelectTemplate(i)
+	}
+
+	m, err := opts.RunTUI(model)
+	if err != nil {
+		return err
+	}
+	if m.CurrentStep != tui.StepDone {
+		return ErrCancelled
+	}
+
+	applyTemplate(m.Entry, templates)
+	content, err := markdown.GenerateMarkdown(m.Entry)
+	if err != nil {
+		return fmt.Errorf("generate markdown: %w", err)
+	}
+	if err := fsys.WriteFile(file, content); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	updateIndex(opts, journalDir, file)
+	fmt.Fprintf(out, "Review saved to: %s\n", file)
+	return nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"journal-cli/internal/domain"
+	"journal-cli/internal/search"
+)
+
+// Search writes the entries matching query, newest first, with the
+// sections that contain its words. limit caps the number of entries
+// written; 0 writes all.
+func Search(opts Options, query string, limit int) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	q, err := search.Parse(query, opts.Clock.Now())
+	if err != nil {
+		return err
+	}
+
+	x, err := openIndex(opts, resolveJournalDir(cfg))
+	if err != nil {
+		return err
+	}
+	results, unreadable := search.Search(x, q)
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No matching entries.")
+		return nil
+	}
+	shown := results
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for i, r := range shown {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, entryLabel(r.Entry))
+		for _, h := range r.Hits {
+			fmt.Fprintf(w, "  %s: %s\n", h.Section, h.Snippet)
+		}
+	}
+	fmt.Fprintf(w, "\n%d of %d matching entries shown.\n", len(shown), len(results))
+	if len(unreadable) > 0 {
+		fmt.Fprintf(w, "Skipped %d files that could not be parsed: %s\n", len(unreadable), strings.Join(unreadable, ", "))
+	}
+	return nil
+}
+
+// entryLabel names an entry in listings: its date and weekday, or the
+// period of a review, and its template.
+func entryLabel(e *domain.JournalEntry) string {
+	label := e.Date.Format("2006-01-02 Mon")
+	if e.Period != domain.PeriodDay {
+		label = e.Period.Name(e.Date) + " " + e.Period.String() + " review"
+	}
+	if e.Template != "" {
+		label += " (" + e.Template + ")"
+	}
+	return label
+}
@@ -0,0 +1,35 @@
+package app
+
+import "fmt"
+
+// Show writes the raw Markdown of the entry for the date selected by opts.
+func Show(opts Options) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	date, err := resolveDate(opts)
+	if err != nil {
+		return err
+	}
+
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+	file := l.Path(resolveJournalDir(cfg), date)
+	if !opts.FS.Exists(file) {
+		return fmt.Errorf("journal file not found: %s", file)
+	}
+
+	data, err := opts.FS.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
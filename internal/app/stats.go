@@ -0,0 +1,155 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/stats"
+)
+
+// PrintStats writes the statistics of the daily entries within
+// rangeExpr (see dateexpr.ParseRange; empty for all), as text or with
+// asJSON set as a JSON object.
+func PrintStats(opts Options, rangeExpr string, asJSON bool) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	journalDir := resolveJournalDir(cfg)
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+	now := opts.Clock.Now()
+	span, err := dateexpr.ParseRange(rangeExpr, now)
+	if err != nil {
+		return err
+	}
+	templates, _, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+
+	x, err := openIndex(opts, journalDir)
+	if err != nil {
+		return err
+	}
+	s := stats.Summarize(x.Daily(l), templates, span, now)
+	if asJSON {
+		return writeStatsJSON(w, s)
+	}
+
+	fmt.Fprintf(w, "Journal:          %s\n", journalDir)
+	fmt.Fprintf(w, "Range:            %s to %s (%s)\n", s.Start.Format("2006-01-02"), s.End.Format("2006-01-02"), plural(s.Days, "day"))
+	fmt.Fprintf(w, "Entries:          %d (%.1f per week, %.1f per month)\n", s.Entries, s.PerWeek(), s.PerMonth())
+	fmt.Fprintf(w, "Current streak:   %s\n", plural(s.CurrentStreak, "day"))
+	if s.LongestStreak > 0 {
+		fmt.Fprintf(w, "Longest streak:   %s, to %s\n", plural(s.LongestStreak, "day"), s.LongestStreakEnd.Format("Monday, 02 Jan 2006"))
+	}
+	fmt.Fprintf(w, "Average words:    %.0f per entry\n", s.AverageWords())
+	if s.RequiredAsked > 0 {
+		fmt.Fprintf(w, "Required answers: %.0f%% (%d of %d)\n", 100*s.RequiredRate(), s.RequiredAnswered, s.RequiredAsked)
+	}
+	if s.TodosPlanned > 0 {
+		fmt.Fprintf(w, "Todos done:       %.0f%% (%d of %d)\n", 100*s.TodoRate(), s.TodosDone, s.TodosPlanned)
+	}
+	if len(s.Months) < 2 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MONTH\tENTRIES\tWORDS\tTODOS DONE")
+	for _, m := range s.Months {
+		todos := "-"
+		if m.TodosPlanned > 0 {
+			todos = fmt.Sprintf("%.0f%% (%d of %d)", 100*m.TodoRate(), m.TodosDone, m.TodosPlanned)
+		}
+		fmt.Fprintf(tw, "%s\t%d/%d\t%d\t%s\n", m.Start.Format("2006-01"), m.Entries, m.Days, m.Words, todos)
+	}
+	return tw.Flush()
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// statsJSON is the JSON form of a stats.Summary. Dates are YYYY-MM-DD and
+// rates between 0 and 1.
+type statsJSON struct {
+	Start            string      `json:"start"`
+	End              string      `json:"end"`
+	Days             int         `json:"days"`
+	Entries          int         `json:"entries"`
+	PerWeek          float64     `json:"entries_per_week"`
+	PerMonth         float64     `json:"entries_per_month"`
+	CurrentStreak    int         `json:"current_streak"`
+	LongestStreak    int         `json:"longest_streak"`
+	LongestStreakEnd string      `json:"longest_streak_end,omitempty"`
+	Words            int         `json:"words"`
+	AverageWords     float64     `json:"average_words"`
+	RequiredAsked    int         `json:"required_asked"`
+	RequiredAnswered int         `json:"required_answered"`
+	RequiredRate     float64     `json:"required_rate"`
+	TodosPlanned     int         `json:"todos_planned"`
+	TodosDone        int         `json:"todos_done"`
+	TodoRate         float64     `json:"todo_rate"`
+	Months           []monthJSON `json:"months"`
+}
+
+type monthJSON struct {
+	Month        string  `json:"month"` // YYYY-MM
+	Days         int     `json:"days"`
+	Entries      int     `json:"entries"`
+	Words        int     `json:"words"`
+	TodosPlanned int     `json:"todos_planned"`
+	TodosDone    int     `json:"todos_done"`
+	TodoRate     float64 `json:"todo_rate"`
+}
+
+func writeStatsJSON(w io.Writer, s stats.Summary) error {
+	out := statsJSON{
+		Start:            s.Start.Format("2006-01-02"),
+		End:              s.End.Format("2006-01-02"),
+		Days:             s.Days,
+		Entries:          s.Entries,
+		PerWeek:          s.PerWeek(),
+		PerMonth:         s.PerMonth(),
+		CurrentStreak:    s.CurrentStreak,
+		LongestStreak:    s.LongestStreak,
+		Words:            s.Words,
+		AverageWords:     s.AverageWords(),
+		RequiredAsked:    s.RequiredAsked,
+		RequiredAnswered: s.RequiredAnswered,
+		RequiredRate:     s.RequiredRate(),
+		TodosPlanned:     s.TodosPlanned,
+		TodosDone:        s.TodosDone,
+		TodoRate:         s.TodoRate(),
+		Months:           make([]monthJSON, 0, len(s.Months)),
+	}
+	if !s.LongestStreakEnd.IsZero() {
+		out.LongestStreakEnd = s.LongestStreakEnd.Format("2006-01-02")
+	}
+	for _, m := range s.Months {
+		out.Months = append(out.Months, monthJSON{
+			Month:        m.Start.Format("2006-01"),
+			Days:         m.Days,
+			Entries:      m.Entries,
+			Words:        m.Words,
+			TodosPlanned: m.TodosPlanned,
+			TodosDone:    m.TodosDone,
+			TodoRate:     m.TodoRate(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"journal-cli/internal/template"
+)
+
+// ListTemplates writes the name and description of every available template.
+func ListTemplates(opts Options) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	templates, diags, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Templates in %s:\n", templatesDir(opts))
+	for _, t := range templates {
+		if t.Description != "" {
+			fmt.Fprintf(w, "  %-20s %s\n", t.Name, t.Description)
+		} else {
+			fmt.Fprintf(w, "  %s\n", t.Name)
+		}
+	}
+	if len(diags) > 0 {
+		fmt.Fprintf(w, "\nProblems (run 'journal templates validate' for details):\n")
+		for _, d := range diags {
+			fmt.Fprintf(w, "  %s\n", d.Short())
+		}
+	}
+	return nil
+}
+
+// ShowTemplate writes the questions of the named template.
+func ShowTemplate(opts Options, name string) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	templates, _, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range templates {
+		if t.Name != name {
+			continue
+		}
+		fmt.Fprintf(w, "%s\n", t.Name)
+		if t.Description != "" {
+			fmt.Fprintf(w, "%s\n", t.Description)
+		}
+		if t.Extends != "" {
+			fmt.Fprintf(w, "Extends: %s\n", t.Extends)
+		}
+		if len(t.Include) > 0 {
+			fmt.Fprintf(w, "Includes: %s\n", strings.Join(t.Include, ", "))
+		}
+		fmt.Fprintln(w)
+		for i, q := range t.Questions {
+			line := fmt.Sprintf("%2d. %s", i+1, q.Title)
+			if q.ID != "" {
+				line += fmt.Sprintf(" [%s]", q.ID)
+			}
+			if d := describeType(q); d != "" {
+				line += " (" + d + ")"
+			}
+			if q.Required {
+				line += " *required"
+			}
+			if q.ShowIf != "" {
+				line += " — only if " + q.ShowIf
+			}
+			fmt.Fprintln(w, line)
+		}
+		return nil
+	}
+	return fmt.Errorf("template not found: %s", name)
+}
+
+// ValidateTemplates checks the given template files, or every template in
+// the templates folder when none are given, and reports each problem with
+// its file, line and column. It fails when a template cannot be loaded, or
+// with strict set, when there are warnings too.
+func ValidateTemplates(opts Options, files []string, strict bool) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+
+	var diags []template.Diagnostic
+	if len(files) == 0 {
+		var err error
+		if _, diags, err = loadTemplates(opts); err != nil {
+			return err
+		}
+	} else {
+		_, diags = template.Validate(opts.FS, files)
+	}
+
+	var errs, warnings int
+	for _, d := range diags {
+		fmt.Fprintln(w, d)
+		if d.Fatal {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	if errs > 0 || (strict && warnings > 0) {
+		return fmt.Errorf("templates have %d errors and %d warnings", errs, warnings)
+	}
+	if warnings > 0 {
+		fmt.Fprintf(w, "Templates are valid (%d warnings).\n", warnings)
+	} else {
+		fmt.Fprintln(w, "Templates are valid.")
+	}
+	return nil
+}
+
+// describeType summarises the answer a typed question expects.
+func describeType(q template.Question) string {
+	switch q.Kind() {
+	case template.TypeScale:
+		lo, hi := q.ScaleRange()
+		return fmt.Sprintf("scale %d-%d", lo, hi)
+	case template.TypeChoice, template.TypeMultiChoice:
+		return fmt.Sprintf("%s: %s", q.Kind(), strings.Join(q.Options, ", "))
+	case template.TypeNumber:
+		if q.Unit != "" {
+			return "number, " + q.Unit
+		}
+		return "number"
+	case template.TypeBoolean, template.TypeList:
+		return string(q.Kind())
+	}
+	return ""
+}
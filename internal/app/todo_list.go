@@ -0,0 +1,203 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/markdown"
+	"journal-cli/internal/todo"
+)
+
+// TodoQuery selects the todos listed by ListTodos and how they are written.
+type TodoQuery struct {
+	Range    string   // Entry dates, see dateexpr.ParseRange; empty for all
+	Statuses []string // Status names, "open" for any unfinished one, or "all"; empty for open
+	Overdue  bool     // Only open todos past their due date
+	Stale    bool     // Only open todos carried over for stale_todo_days or more
+	Tag      string
+	Text     string
+	Format   string // table (default), json or markdown
+}
+
+// ListTodos writes the todos of every entry in the journal that match q,
+// each once in its latest state.
+func ListTodos(opts Options, q TodoQuery) error {
+	opts = opts.withDefaults()
+	w := opts.Stdout
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	journalDir := resolveJournalDir(cfg)
+	l, err := cfg.Layout()
+	if err != nil {
+		return err
+	}
+
+	today := dateexpr.Day(opts.Clock.Now())
+	span, err := dateexpr.ParseRange(q.Range, today)
+	if err != nil {
+		return err
+	}
+	filter := todo.Filter{Overdue: q.Overdue, Tag: q.Tag, Text: q.Text, Today: today}
+	if q.Stale {
+		filter.Stale = cfg.StaleTodoDays()
+	}
+	if filter.Statuses, err = parseStatuses(q.Statuses); err != nil {
+		return err
+	}
+
+	write, ok := todoWriters[q.Format]
+	if !ok {
+		return fmt.Errorf("unknown format %q (use table, json or markdown)", q.Format)
+	}
+
+	x, err := openIndex(opts, journalDir)
+	if err != nil {
+		return err
+	}
+	items, unreadable := todo.Collect(x, l, span)
+	var matched []todo.Item
+	for _, it := range items {
+		if filter.Match(it) {
+			matched = append(matched, it)
+		}
+	}
+	if err := write(w, matched, today); err != nil {
+		return err
+	}
+	if len(unreadable) > 0 && (q.Format == "" || q.Format == "table") {
+		fmt.Fprintf(w, "\nSkipped %d entries that could not be parsed:\n", len(unreadable))
+		for _, path := range unreadable {
+			fmt.Fprintf(w, "  %s\n", path)
+		}
+	}
+	return nil
+}
+
+// parseStatuses resolves the status names of a TodoQuery.
+func parseStatuses(names []string) ([]domain.Status, error) {
+	if len(names) == 0 {
+		names = []string{"open"}
+	}
+	var statuses []domain.Status
+	for _, name := range names {
+		switch name {
+		case "all":
+			return nil, nil
+		case "open":
+			statuses = append(statuses, domain.StatusOpen, domain.StatusInProgress, domain.StatusDeferred)
+		default:
+			s, err := domain.ParseStatus(name)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses, nil
+}
+
+var todoWriters = map[string]func(io.Writer, []todo.Item, time.Time) error{
+	"":         writeTodoTable,
+	"table":    writeTodoTable,
+	"json":     writeTodoJSON,
+	"markdown": writeTodoMarkdown,
+}
+
+func writeTodoTable(w io.Writer, items []todo.Item, today time.Time) error {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No matching todos.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEEN\tSTATUS\tPRIORITY\tDUE\tCARRIED\tTODO")
+	for _, it := range items {
+		due := ""
+		if !it.Due.IsZero() {
+			due = it.Due.Format("2006-01-02")
+			if it.Overdue(today) {
+				due += " (overdue)"
+			}
+		}
+		priority := ""
+		if it.Priority != domain.PriorityNone {
+			priority = it.Priority.String()
+		}
+		carried := ""
+		if it.Carried > 0 {
+			carried = strconv.Itoa(it.Carried) + "x"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			it.Date.Format("2006-01-02"), it.Status, priority, due, carried, it.Text)
+	}
+	return tw.Flush()
+}
+
+// todoJSON is the JSON form of a listed todo. Dates are YYYY-MM-DD.
+type todoJSON struct {
+	ID       string   `json:"id,omitempty"`
+	Text     string   `json:"text"`
+	Status   string   `json:"status"`
+	Priority string   `json:"priority,omitempty"`
+	Due      string   `json:"due,omitempty"`
+	Overdue  bool     `json:"overdue,omitempty"`
+	Created  string   `json:"created,omitempty"`
+	Carried  int      `json:"carried"`
+	Tags     []string `json:"tags,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	Seen     string   `json:"seen"` // Date of the entry it was last seen in
+	Path     string   `json:"path"`
+	Backlog  bool     `json:"backlog,omitempty"`
+}
+
+func writeTodoJSON(w io.Writer, items []todo.Item, today time.Time) error {
+	day := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	out := make([]todoJSON, 0, len(items))
+	for _, it := range items {
+		j := todoJSON{
+			ID:      it.ID,
+			Text:    it.Text,
+			Status:  it.Status.String(),
+			Due:     day(it.Due),
+			Overdue: it.Overdue(today),
+			Created: day(it.Created),
+			Carried: it.Carried,
+			Tags:    it.Tags(),
+			Project: it.Project(),
+			Seen:    day(it.Date),
+			Path:    filepath.ToSlash(it.Path),
+			Backlog: it.Backlog,
+		}
+		if it.Priority != domain.PriorityNone {
+			j.Priority = it.Priority.String()
+		}
+		out = append(out, j)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// writeTodoMarkdown writes the todos as a task list that can be pasted
+// into a note; Obsidian Tasks reads the metadata.
+func writeTodoMarkdown(w io.Writer, items []todo.Item, _ time.Time) error {
+	for _, it := range items {
+		if _, err := fmt.Fprintln(w, markdown.FormatTodo(it.Todo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
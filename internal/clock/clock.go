@@ -0,0 +1,22 @@
+// Package clock abstracts the current time so that date-dependent logic
+// can be tested against a fixed moment.
+package clock
+
+import "time"
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the real wall clock.
+type System struct{}
+
+// Now returns time.Now().
+func (System) Now() time.Time { return time.Now() }
+
+// Fixed is a clock that always reports the same moment.
+type Fixed time.Time
+
+// Now returns the fixed moment.
+func (f Fixed) Now() time.Time { return time.Time(f) }
@@ -1,26 +1,100 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 
+	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
+
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	ObsidianVault string `yaml:"obsidian_vault"`
-	JournalDir    string `yaml:"journal_dir"` // Relative to ObsidianVault
+	JournalDir    string `yaml:"journal_dir"`  // Relative to ObsidianVault
+	PathPattern   string `yaml:"path_pattern"` // Daily entry paths in JournalDir; see layout.Parse
+
+	// How many days back to look for the entry the backlog is carried
+	// over from; see BacklogLookback.
+	BacklogLookbackDays int `yaml:"backlog_lookback_days"`
+	// Open todos carried over for this many days are flagged as stale;
+	// see StaleTodoDays.
+	StaleAfterDays int `yaml:"stale_todo_days"`
+
+	// Template selection for new entries; see TemplateFor.
+	DefaultTemplate    string         `yaml:"default_template"`
+	TemplateRules      []TemplateRule `yaml:"template_rules"`
+	SkipTemplatePicker bool           `yaml:"skip_template_picker"` // Start at the first step when a template is selected
+
+	// Todos added to new entries on the dates they match; see RecurringFor.
+	RecurringTodos []RecurringTodo `yaml:"recurring_todos"`
 }
 
-func LoadConfig() (*Config, error) {
+// Layout returns the layout of daily entries set by path_pattern, the flat
+// layout.Default when unset.
+func (c *Config) Layout() (layout.Layout, error) {
+	if c.PathPattern == "" {
+		return layout.Layout{}, nil
+	}
+	l, err := layout.Parse(c.PathPattern)
+	if err != nil {
+		return layout.Layout{}, fmt.Errorf("config path_pattern: %w", err)
+	}
+	return l, nil
+}
+
+// DefaultBacklogLookback is the number of days searched for the previous
+// entry when backlog_lookback_days is not set.
+const DefaultBacklogLookback = 30
+
+// BacklogLookback returns how many days before a new entry to search for
+// the entry its backlog is carried over from.
+func (c *Config) BacklogLookback() int {
+	if c.BacklogLookbackDays <= 0 {
+		return DefaultBacklogLookback
+	}
+	return c.BacklogLookbackDays
+}
+
+// DefaultStaleTodoDays is the age in days at which carried-over todos are
+// flagged when stale_todo_days is not set.
+const DefaultStaleTodoDays = 7
+
+// StaleTodoDays returns the age in days at which carried-over todos are
+// flagged as stale.
+func (c *Config) StaleTodoDays() int {
+	if c.StaleAfterDays <= 0 {
+		return DefaultStaleTodoDays
+	}
+	return c.StaleAfterDays
+}
+
+// Dir returns the directory holding config.yaml and the templates folder.
+func Dir() (string, error) {
 	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "journal-cli"), nil
+}
+
+func LoadConfig() (*Config, error) {
+	dir, err := Dir()
 	if err != nil {
 		return nil, err
 	}
+	return LoadConfigFrom(fs.OS{}, filepath.Join(dir, "config.yaml"))
+}
 
-	configPath := filepath.Join(configDir, "journal-cli", "config.yaml")
-	data, err := os.ReadFile(configPath)
-	if os.IsNotExist(err) {
+// LoadConfigFrom reads the config file at path, falling back to the
+// defaults when the file does not exist.
+func LoadConfigFrom(fsys fs.FS, configPath string) (*Config, error) {
+	data, err := fsys.ReadFile(configPath)
+	if errors.Is(err, iofs.ErrNotExist) {
 		// Return default config if not found
 		return &Config{
 			ObsidianVault: "", // User must set this
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -55,3 +58,129 @@ func TestLoadConfigFromFile(t *testing.T) {
 		t.Fatalf("vault mismatch: got %s want %s", got.ObsidianVault, c.ObsidianVault)
 	}
 }
+
+func TestTemplateFor(t *testing.T) {
+	data := `
+default_template: daily
+template_rules:
+  - day_of_month: [1, -1]
+    template: monthly
+  - days: weekdays
+    template: work
+  - days: [sat, Sunday]
+    template: gentle
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for date, want := range map[string]string{
+		"2025-12-01": "monthly", // first of the month, a Monday
+		"2025-12-31": "monthly", // last of the month
+		"2025-12-02": "work",
+		"2025-12-06": "gentle",
+		"2025-12-07": "gentle",
+	} {
+		d, _ := time.Parse("2006-01-02", date)
+		if got := cfg.TemplateFor(d); got != want {
+			t.Errorf("TemplateFor(%s) = %q, want %q", date, got, want)
+		}
+	}
+
+	cfg.TemplateRules = cfg.TemplateRules[:1]
+	d, _ := time.Parse("2006-01-02", "2025-12-02")
+	if got := cfg.TemplateFor(d); got != "daily" {
+		t.Errorf("expected default_template when no rule matches, got %q", got)
+	}
+}
+
+func TestDaysRanges(t *testing.T) {
+	var rule TemplateRule
+	if err := yaml.Unmarshal([]byte("days: fri-mon\n"), &rule); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := Days{time.Friday, time.Saturday, time.Sunday, time.Monday}
+	if !slices.Equal(rule.Days, want) {
+		t.Errorf("fri-mon = %v, want %v", rule.Days, want)
+	}
+	if err := yaml.Unmarshal([]byte("days: [mon, funday]\n"), &rule); err == nil {
+		t.Errorf("expected an error for an unknown weekday")
+	}
+}
+
+func TestRecurringFor(t *testing.T) {
+	data := `
+recurring_todos:
+  - text: Plan the week
+    days: mon
+  - text: Pay rent
+    day_of_month: [1]
+  - text: Water plants
+    rule: FREQ=DAILY;INTERVAL=3
+    start: 2025-12-01
+  - text: Sprint review
+    rule: RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR
+    start: 2025-12-05
+  - text: Book club
+    rule: FREQ=MONTHLY;BYDAY=-1TH
+  - text: Renew domain
+    rule: FREQ=YEARLY;UNTIL=20261231
+    start: 2025-12-04
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for date, want := range map[string][]string{
+		"2025-11-28": nil, // before the start dates
+		"2025-12-01": {"Plan the week", "Pay rent", "Water plants"},
+		"2025-12-04": {"Water plants", "Renew domain"},
+		"2025-12-05": {"Sprint review"},
+		"2025-12-12": nil, // off week
+		"2025-12-19": {"Water plants", "Sprint review"},
+		"2025-12-25": {"Water plants", "Book club"},
+		"2026-12-04": {"Sprint review", "Renew domain"},
+		"2027-12-04": nil, // after UNTIL
+	} {
+		d, _ := time.Parse("2006-01-02", date)
+		if got := cfg.RecurringFor(d); !slices.Equal(got, want) {
+			t.Errorf("RecurringFor(%s) = %q, want %q", date, got, want)
+		}
+	}
+}
+
+// TestRecurringStartLocal checks that a start date holds on the same
+// calendar day west of UTC, where its midnight UTC is the evening before.
+func TestRecurringStartLocal(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("recurring_todos:\n  - text: Stand-up\n    days: weekdays\n    start: 2026-01-13\n"), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	est := time.FixedZone("EST", -5*60*60)
+	for day, want := range map[int][]string{12: nil, 13: {"Stand-up"}, 14: {"Stand-up"}} {
+		d := time.Date(2026, 1, day, 9, 0, 0, 0, est)
+		if got := cfg.RecurringFor(d); !slices.Equal(got, want) {
+			t.Errorf("RecurringFor(%s) = %q, want %q", d.Format("2006-01-02"), got, want)
+		}
+	}
+}
+
+func TestRecurringTodoErrors(t *testing.T) {
+	for data, want := range map[string]string{
+		"text: x\n":                                  "needs days",
+		"days: mon\n":                                "without text",
+		"text: x\nrule: FREQ=HOURLY\n":               "FREQ must be",
+		"text: x\nrule: BYDAY=MO\n":                  "no FREQ",
+		"text: x\nrule: FREQ=DAILY;COUNT=3\n":        "COUNT is not supported",
+		"text: x\nrule: FREQ=DAILY;INTERVAL=2\n":     "needs a start date",
+		"text: x\nrule: FREQ=WEEKLY\n":               "start date",
+		"text: x\nrule: FREQ=WEEKLY;BYDAY=1MO\n":     "FREQ=MONTHLY",
+		"text: x\nrule: FREQ=MONTHLY;BYMONTHDAY=0\n": "between",
+	} {
+		var r RecurringTodo
+		err := yaml.Unmarshal([]byte(data), &r)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", data, err, want)
+		}
+	}
+}
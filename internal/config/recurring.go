@@ -0,0 +1,334 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecurringTodo is a todo added to every new entry whose date matches. Days
+// and DaysOfMonth work as in TemplateRule; Rule takes an iCalendar RRULE for
+// schedules they cannot express. A definition needs all of its conditions
+// to match and must set at least one.
+type RecurringTodo struct {
+	Text        string    `yaml:"text"`
+	Days        Days      `yaml:"days"`
+	DaysOfMonth []int     `yaml:"day_of_month"`
+	Rule        Rule      `yaml:"rule"`
+	Start       time.Time `yaml:"start"` // First date, and the anchor of rule intervals
+}
+
+func (r *RecurringTodo) UnmarshalYAML(n *yaml.Node) error {
+	type plain RecurringTodo
+	if err := n.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	if strings.TrimSpace(r.Text) == "" {
+		return fmt.Errorf("line %d: recurring todo without text", n.Line)
+	}
+	if len(r.Days) == 0 && len(r.DaysOfMonth) == 0 && r.Rule.IsZero() {
+		return fmt.Errorf("line %d: recurring todo %q needs days, day_of_month or rule", n.Line, r.Text)
+	}
+	if err := r.Rule.validate(r.Start); err != nil {
+		return fmt.Errorf("line %d: recurring todo %q: %w", n.Line, r.Text, err)
+	}
+	return nil
+}
+
+// Matches reports whether the todo recurs on date.
+func (r RecurringTodo) Matches(date time.Time) bool {
+	date = dateexpr.Day(date)
+	if !r.Start.IsZero() {
+		// The start is a calendar date, decoded at midnight UTC; take the
+		// same day in date's location rather than converting it.
+		start := time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day(), 0, 0, 0, 0, date.Location())
+		if date.Before(start) {
+			return false
+		}
+	}
+	rule := TemplateRule{Days: r.Days, DaysOfMonth: r.DaysOfMonth}
+	if !rule.Matches(date) {
+		return false
+	}
+	return r.Rule.IsZero() || r.Rule.matches(date, r.Start)
+}
+
+// RecurringFor returns the text of the recurring todos due on date, in
+// config order.
+func (c *Config) RecurringFor(date time.Time) []string {
+	var texts []string
+	for _, r := range c.RecurringTodos {
+		if r.Matches(date) {
+			texts = append(texts, strings.TrimSpace(r.Text))
+		}
+	}
+	return texts
+}
+
+// Freq is the base period of a Rule.
+type Freq int
+
+const (
+	Daily Freq = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var freqNames = map[string]Freq{"DAILY": Daily, "WEEKLY": Weekly, "MONTHLY": Monthly, "YEARLY": Yearly}
+
+// WeekdayNum is a BYDAY value: a weekday, and in monthly rules optionally
+// its occurrence in the month (1 for the first, -1 for the last).
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0 for every occurrence
+}
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Rule is the subset of an RFC 5545 recurrence rule that makes sense for
+// days: FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL, BYDAY,
+// BYMONTHDAY, BYMONTH and UNTIL, such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO".
+// Weeks start on Monday.
+type Rule struct {
+	Freq       Freq
+	Interval   int // 1 when unset
+	ByDay      []WeekdayNum
+	ByMonthDay []int // 1-31, or -1 for the last day, -2 for the one before, ...
+	ByMonth    []time.Month
+	Until      time.Time // Last date, inclusive; zero for none
+}
+
+// IsZero reports whether no rule is set.
+func (r Rule) IsZero() bool {
+	return r.Freq == 0
+}
+
+func (r *Rule) UnmarshalYAML(n *yaml.Node) error {
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+	rule, err := ParseRule(s)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	*r = rule
+	return nil
+}
+
+// ParseRule parses an RRULE value, with or without the "RRULE:" prefix.
+func ParseRule(s string) (Rule, error) {
+	var r Rule
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("rule part %q is not NAME=VALUE", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			if r.Freq, ok = freqNames[strings.ToUpper(value)]; !ok {
+				err = fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("INTERVAL must be at least 1")
+			}
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, 1, 12)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "UNTIL":
+			if len(value) < 8 {
+				err = fmt.Errorf("UNTIL must be a date such as 20261231")
+			} else if r.Until, err = time.Parse("20060102", value[:8]); err != nil {
+				err = fmt.Errorf("UNTIL must be a date such as 20261231")
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				err = fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("%s is not supported", strings.ToUpper(key))
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", s, err)
+		}
+	}
+	if r.Freq == 0 {
+		return Rule{}, fmt.Errorf("rule %q has no FREQ", s)
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly {
+			return Rule{}, fmt.Errorf("rule %q: numbered BYDAY values need FREQ=MONTHLY", s)
+		}
+	}
+	return r, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, v := range strings.Split(strings.ToUpper(value), ",") {
+		v = strings.TrimSpace(v)
+		if len(v) < 2 {
+			return nil, fmt.Errorf("unknown BYDAY value %q", v)
+		}
+		wd, ok := rruleDays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("unknown BYDAY value %q", v)
+		}
+		n := 0
+		if num := v[:len(v)-2]; num != "" {
+			var err error
+			if n, err = strconv.Atoi(num); err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("unknown BYDAY value %q", v)
+			}
+		}
+		days = append(days, WeekdayNum{Weekday: wd, N: n})
+	}
+	return days, nil
+}
+
+func parseInts(value string, lo, hi int) ([]int, error) {
+	var out []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n == 0 || n < lo || n > hi {
+			return nil, fmt.Errorf("%q is not between %d and %d", v, lo, hi)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// validate checks that the rule can be evaluated with the given start
+// date: intervals, and periods whose day is not given, are taken from it.
+func (r Rule) validate(start time.Time) error {
+	if r.IsZero() || !start.IsZero() {
+		return nil
+	}
+	if r.Interval > 1 {
+		return fmt.Errorf("INTERVAL needs a start date")
+	}
+	switch {
+	case r.Freq == Weekly && len(r.ByDay) == 0,
+		r.Freq == Monthly && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0,
+		r.Freq == Yearly && (len(r.ByMonth) == 0 || len(r.ByDay) == 0 && len(r.ByMonthDay) == 0):
+		return fmt.Errorf("rule needs BY parts for the day or a start date")
+	}
+	return nil
+}
+
+// matches reports whether date is an occurrence of the rule starting on
+// start. date must be a calendar day (see dateexpr.Day).
+func (r Rule) matches(date, start time.Time) bool {
+	if !r.Until.IsZero() && date.After(time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 0, 0, 0, 0, date.Location())) {
+		return false
+	}
+	if !start.IsZero() {
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, date.Location())
+	}
+
+	// Filters, with the parts the rule leaves out taken from the start date.
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, date.Month()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !matchMonthDay(r.ByMonthDay, date) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(wd WeekdayNum) bool { return wd.matches(date) }) {
+		return false
+	}
+	byDay := len(r.ByDay) > 0 || len(r.ByMonthDay) > 0
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 && date.Weekday() != start.Weekday() {
+			return false
+		}
+	case Monthly:
+		if !byDay && date.Day() != start.Day() {
+			return false
+		}
+	case Yearly:
+		if len(r.ByMonth) == 0 && date.Month() != start.Month() {
+			return false
+		}
+		if !byDay && date.Day() != start.Day() {
+			return false
+		}
+	}
+
+	if r.Interval <= 1 {
+		return true
+	}
+	var n int
+	switch r.Freq {
+	case Daily:
+		n = daysBetween(start, date)
+	case Weekly:
+		n = daysBetween(weekStart(start), weekStart(date)) / 7
+	case Monthly:
+		n = (date.Year()-start.Year())*12 + int(date.Month()-start.Month())
+	case Yearly:
+		n = date.Year() - start.Year()
+	}
+	return n%r.Interval == 0
+}
+
+func (wd WeekdayNum) matches(date time.Time) bool {
+	if date.Weekday() != wd.Weekday {
+		return false
+	}
+	switch {
+	case wd.N > 0:
+		return (date.Day()-1)/7+1 == wd.N
+	case wd.N < 0:
+		last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+		return (last-date.Day())/7+1 == -wd.N
+	}
+	return true
+}
+
+// matchMonthDay reports whether date is one of days, where negative days
+// count from the end of the month.
+func matchMonthDay(days []int, date time.Time) bool {
+	day := date.Day()
+	last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+	return slices.Contains(days, day) || slices.Contains(days, day-last-1)
+}
+
+// weekStart returns the Monday of the week containing t.
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// daysBetween counts calendar days from a to b, ignoring daylight saving
+// changes.
+func daysBetween(a, b time.Time) int {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(b).Sub(day(a)).Hours() / 24)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateRule selects a template for entries whose date matches. A rule
+// with both Days and DaysOfMonth needs both to match; one with neither
+// matches every date.
+type TemplateRule struct {
+	Template    string `yaml:"template"`
+	Days        Days   `yaml:"days"`
+	DaysOfMonth []int  `yaml:"day_of_month"` // 1-31, or -1 for the last day, -2 for the one before, ...
+}
+
+// Matches reports whether the rule applies to date.
+func (r TemplateRule) Matches(date time.Time) bool {
+	if len(r.Days) > 0 && !slices.Contains(r.Days, date.Weekday()) {
+		return false
+	}
+	return len(r.DaysOfMonth) == 0 || matchMonthDay(r.DaysOfMonth, date)
+}
+
+// TemplateFor returns the template for an entry on date: that of the first
+// matching rule, else DefaultTemplate. It returns "" when neither applies.
+func (c *Config) TemplateFor(date time.Time) string {
+	for _, r := range c.TemplateRules {
+		if r.Matches(date) {
+			return r.Template
+		}
+	}
+	return c.DefaultTemplate
+}
+
+// Days is a set of weekdays, written as a name ("mon", "Tuesday"), a range
+// ("mon-fri"), "weekdays", "weekends", or a list of these.
+type Days []time.Weekday
+
+var dayGroups = map[string]Days{
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+func (d *Days) UnmarshalYAML(n *yaml.Node) error {
+	var names []string
+	switch n.Kind {
+	case yaml.ScalarNode:
+		names = []string{n.Value}
+	default:
+		if err := n.Decode(&names); err != nil {
+			return err
+		}
+	}
+	*d = nil
+	for _, name := range names {
+		days, err := parseDays(name)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		for _, wd := range days {
+			if !slices.Contains(*d, wd) {
+				*d = append(*d, wd)
+			}
+		}
+	}
+	return nil
+}
+
+// parseDays reads one entry of a Days list.
+func parseDays(s string) (Days, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if days, ok := dayGroups[s]; ok {
+		return days, nil
+	}
+	from, to, isRange := strings.Cut(s, "-")
+	first, err := dateexpr.ParseWeekday(from)
+	if err != nil || !isRange {
+		return Days{first}, err
+	}
+	last, err := dateexpr.ParseWeekday(to)
+	if err != nil {
+		return nil, err
+	}
+	// Ranges wrap around the end of the week, so "fri-mon" is four days.
+	days := Days{first}
+	for wd := first; wd != last; {
+		wd = (wd + 1) % 7
+		days = append(days, wd)
+	}
+	return days, nil
+}
@@ -0,0 +1,392 @@
+// Package dateexpr parses the date and date-range expressions accepted on
+// the command line, such as "yesterday", "-2d", "last friday", "2025-W52" or
+// "2025-12", relative to a reference time.
+package dateexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	offsetRe   = regexp.MustCompile(`^([+-])(\d+)\s*([a-z]*)$`)
+	isoWeekRe  = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	monthRe    = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	yearRe     = regexp.MustCompile(`^(\d{4})$`)
+	slashDayRe = regexp.MustCompile(`^\d{1,2}[/.]\d{1,2}([/.]\d{2,4})?$`)
+)
+
+// weekdayNames maps every accepted spelling to its weekday.
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Range is an inclusive span of calendar days.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero reports whether r is the zero (unbounded) range.
+func (r Range) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// Contains reports whether the calendar day of t falls within r. The zero
+// Range contains every day.
+func (r Range) Contains(t time.Time) bool {
+	if r.IsZero() {
+		return true
+	}
+	d := Day(t.In(r.Start.Location()))
+	return !d.Before(r.Start) && !d.After(r.End)
+}
+
+// Days returns the number of calendar days in r.
+func (r Range) Days() int {
+	return int(r.End.Sub(r.Start).Hours()/24+0.5) + 1
+}
+
+func (r Range) String() string {
+	if r.Start.Equal(r.End) {
+		return r.Start.Format("2006-01-02")
+	}
+	return r.Start.Format("2006-01-02") + ".." + r.End.Format("2006-01-02")
+}
+
+// Parse resolves expr to a single calendar date (midnight in now's
+// location). An empty expression means today.
+//
+// Supported forms:
+//
+//	2025-12-30             an ISO date
+//	today, yesterday, tomorrow
+//	-3d, +2d, -1w, -1mo, +1y
+//	                       days, weeks, months or years relative to today
+//	friday, fri            the most recent Friday, today included
+//	last friday            the most recent Friday before today
+//	next friday            the first Friday after today
+//
+// Expressions that name a span, such as "2025-W52" or "2025-12", are
+// rejected; use ParseRange for those.
+func Parse(expr string, now time.Time) (time.Time, error) {
+	s := normalize(expr)
+	if d, ok, err := parseDay(s, now); ok || err != nil {
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q: %w", expr, err)
+		}
+		return d, nil
+	}
+	if _, ok, _ := parseSpan(s, now); ok {
+		return time.Time{}, fmt.Errorf("%q is a range of days, not a single date", expr)
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q (try YYYY-MM-DD, yesterday, -2d or last friday)", expr)
+}
+
+// ParseRange resolves expr to an inclusive range of days. Besides every
+// single-date form accepted by Parse (a one-day range) it understands:
+//
+//	2025-W52               an ISO week (Monday to Sunday)
+//	2025-12                a month
+//	2025                   a year
+//	this week, last week, this month, last month, this year, last year
+//	last 7d, last 2w       the given number of days up to and including today
+//	<from>..<to>           any two single dates, e.g. 2025-12-01..yesterday
+//
+// An empty expression means all time and returns the zero Range.
+func ParseRange(expr string, now time.Time) (Range, error) {
+	s := normalize(expr)
+	if s == "" {
+		return Range{}, nil
+	}
+
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		start, err := boundary(from, now, true)
+		if err != nil {
+			return Range{}, err
+		}
+		end, err := boundary(to, now, false)
+		if err != nil {
+			return Range{}, err
+		}
+		if end.Before(start) {
+			return Range{}, fmt.Errorf("range %q ends before it starts", expr)
+		}
+		return Range{Start: start, End: end}, nil
+	}
+
+	if r, ok, err := parseSpan(s, now); ok || err != nil {
+		if err != nil {
+			return Range{}, fmt.Errorf("%q: %w", expr, err)
+		}
+		return r, nil
+	}
+
+	d, ok, err := parseDay(s, now)
+	if err != nil {
+		return Range{}, fmt.Errorf("%q: %w", expr, err)
+	}
+	if !ok {
+		return Range{}, fmt.Errorf("unrecognized date range %q (try 2025-12, 2025-W52, last week or 2025-12-01..2025-12-31)", expr)
+	}
+	return Range{Start: d, End: d}, nil
+}
+
+// Day truncates t to midnight in its own location.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// ISOWeekStart returns the Monday starting ISO week w of year.
+func ISOWeekStart(year, week int, loc *time.Location) time.Time {
+	// January 4th is always in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7 // days since Monday
+	return jan4.AddDate(0, 0, -offset+(week-1)*7)
+}
+
+func normalize(expr string) string {
+	return strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+}
+
+// boundary resolves one side of a "from..to" range. Either side may itself
+// be a span, in which case its first or last day is used.
+func boundary(s string, now time.Time, start bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("range is missing its %s", map[bool]string{true: "start", false: "end"}[start])
+	}
+	r, err := ParseRange(s, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if start {
+		return r.Start, nil
+	}
+	return r.End, nil
+}
+
+// parseDay handles the single-date forms. ok is false when s is not a
+// single-date expression at all.
+func parseDay(s string, now time.Time) (time.Time, bool, error) {
+	today := Day(now)
+
+	switch s {
+	case "", "today":
+		return today, true, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true, nil
+	}
+
+	if len(s) == 10 && s[4] == '-' && s[7] == '-' {
+		d, err := time.ParseInLocation("2006-01-02", s, now.Location())
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date, expected YYYY-MM-DD")
+		}
+		return d, true, nil
+	}
+
+	if slashDayRe.MatchString(s) {
+		return time.Time{}, false, fmt.Errorf("ambiguous date: day and month order is unclear, use YYYY-MM-DD")
+	}
+
+	if m := offsetRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid offset")
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		d, err := addOffset(today, n, m[3])
+		return d, err == nil, err
+	}
+
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 1:
+		if wd, ok, err := weekday(fields[0]); ok || err != nil {
+			return previousWeekday(today, wd, true), ok, err
+		}
+	case len(fields) == 2 && (fields[0] == "last" || fields[0] == "next"):
+		wd, ok, err := weekday(fields[1])
+		if !ok || err != nil {
+			return time.Time{}, false, err
+		}
+		if fields[0] == "last" {
+			return previousWeekday(today, wd, false), true, nil
+		}
+		return nextWeekday(today, wd), true, nil
+	}
+
+	return time.Time{}, false, nil
+}
+
+// parseSpan handles the multi-day forms.
+func parseSpan(s string, now time.Time) (Range, bool, error) {
+	today := Day(now)
+	loc := now.Location()
+
+	if m := isoWeekRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		if week < 1 || week > isoWeeksInYear(year) {
+			return Range{}, false, fmt.Errorf("week %d does not exist in %d", week, year)
+		}
+		start := ISOWeekStart(year, week, loc)
+		return Range{Start: start, End: start.AddDate(0, 0, 6)}, true, nil
+	}
+
+	if m := monthRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Range{}, false, fmt.Errorf("invalid month %d", month)
+		}
+		return monthRange(year, time.Month(month), loc), true, nil
+	}
+
+	if m := yearRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return Range{
+			Start: time.Date(year, time.January, 1, 0, 0, 0, 0, loc),
+			End:   time.Date(year, time.December, 31, 0, 0, 0, 0, loc),
+		}, true, nil
+	}
+
+	switch s {
+	case "this week", "last week":
+		start := previousWeekday(today, time.Monday, true)
+		if s == "last week" {
+			start = start.AddDate(0, 0, -7)
+		}
+		return Range{Start: start, End: start.AddDate(0, 0, 6)}, true, nil
+	case "this month":
+		return monthRange(today.Year(), today.Month(), loc), true, nil
+	case "last month":
+		prev := time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, loc)
+		return monthRange(prev.Year(), prev.Month(), loc), true, nil
+	case "this year", "last year":
+		year := today.Year()
+		if s == "last year" {
+			year--
+		}
+		return Range{
+			Start: time.Date(year, time.January, 1, 0, 0, 0, 0, loc),
+			End:   time.Date(year, time.December, 31, 0, 0, 0, 0, loc),
+		}, true, nil
+	}
+
+	if rest, ok := strings.CutPrefix(s, "last "); ok {
+		m := offsetRe.FindStringSubmatch("-" + rest)
+		if m == nil {
+			return Range{}, false, nil
+		}
+		n, _ := strconv.Atoi(m[2])
+		if n < 1 {
+			return Range{}, false, fmt.Errorf("range must cover at least one day")
+		}
+		start, err := addOffset(today, -n, m[3])
+		if err != nil {
+			return Range{}, false, err
+		}
+		return Range{Start: start.AddDate(0, 0, 1), End: today}, true, nil
+	}
+
+	return Range{}, false, nil
+}
+
+func addOffset(today time.Time, n int, unit string) (time.Time, error) {
+	switch unit {
+	case "d", "day", "days":
+		return today.AddDate(0, 0, n), nil
+	case "w", "week", "weeks":
+		return today.AddDate(0, 0, 7*n), nil
+	case "mo", "month", "months":
+		return today.AddDate(0, n, 0), nil
+	case "y", "year", "years":
+		return today.AddDate(n, 0, 0), nil
+	case "":
+		return time.Time{}, fmt.Errorf("ambiguous offset: add a unit, e.g. %+dd or %+dw", n, n)
+	case "m":
+		return time.Time{}, fmt.Errorf("ambiguous unit \"m\": use \"mo\" for months")
+	}
+	return time.Time{}, fmt.Errorf("unknown unit %q (use d, w, mo or y)", unit)
+}
+
+// ParseWeekday resolves a weekday name or unambiguous abbreviation such as
+// "mon", "Tues" or "friday".
+func ParseWeekday(s string) (time.Weekday, error) {
+	wd, ok, err := weekday(strings.ToLower(strings.TrimSpace(s)))
+	if err == nil && !ok {
+		err = fmt.Errorf("unknown weekday %q", s)
+	}
+	return wd, err
+}
+
+// weekday resolves a weekday name or abbreviation. Prefixes that match
+// more than one day, such as "t" or "s", are reported as ambiguous.
+func weekday(s string) (time.Weekday, bool, error) {
+	if wd, ok := weekdayNames[s]; ok {
+		return wd, true, nil
+	}
+	if s == "" || strings.Trim(s, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return 0, false, nil
+	}
+	var match time.Weekday
+	found := 0
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.HasPrefix(strings.ToLower(wd.String()), s) {
+			match = wd
+			found++
+		}
+	}
+	switch found {
+	case 0:
+		return 0, false, nil
+	case 1:
+		return match, true, nil
+	}
+	return 0, false, fmt.Errorf("ambiguous weekday %q", s)
+}
+
+// previousWeekday returns the most recent wd on or before today
+// (strictly before when includeToday is false).
+func previousWeekday(today time.Time, wd time.Weekday, includeToday bool) time.Time {
+	diff := (int(today.Weekday()) - int(wd) + 7) % 7
+	if diff == 0 && !includeToday {
+		diff = 7
+	}
+	return today.AddDate(0, 0, -diff)
+}
+
+// nextWeekday returns the first wd strictly after today.
+func nextWeekday(today time.Time, wd time.Weekday) time.Time {
+	diff := (int(wd) - int(today.Weekday()) + 7) % 7
+	if diff == 0 {
+		diff = 7
+	}
+	return today.AddDate(0, 0, diff)
+}
+
+func monthRange(year int, month time.Month, loc *time.Location) Range {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return Range{Start: start, End: start.AddDate(0, 1, -1)}
+}
+
+func isoWeeksInYear(year int) int {
+	_, w := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return w
+}
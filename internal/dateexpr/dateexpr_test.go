@@ -0,0 +1,159 @@
+package dateexpr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedNow is a Tuesday.
+var fixedNow = time.Date(2025, 12, 30, 15, 4, 5, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "2025-12-30"},
+		{"today", "2025-12-30"},
+		{"Yesterday", "2025-12-29"},
+		{"tomorrow", "2025-12-31"},
+		{"2025-12-01", "2025-12-01"},
+		{"-2d", "2025-12-28"},
+		{"+1d", "2025-12-31"},
+		{"-3 days", "2025-12-27"},
+		{"-1w", "2025-12-23"},
+		{"+1w", "2026-01-06"},
+		{"-1mo", "2025-11-30"},
+		{"-1y", "2024-12-30"},
+		{"friday", "2025-12-26"},
+		{"fri", "2025-12-26"},
+		{"tuesday", "2025-12-30"},
+		{"thu", "2025-12-25"},
+		{"last friday", "2025-12-26"},
+		{"last  Tuesday", "2025-12-23"},
+		{"next monday", "2026-01-05"},
+		{"next tue", "2026-01-06"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr, fixedNow)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.expr, got.Format("2006-01-02"), tt.want)
+			}
+			if got.Hour() != 0 || got.Minute() != 0 {
+				t.Errorf("Parse(%q) should return midnight, got %v", tt.expr, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"someday", "unrecognized date"},
+		{"2025-13-01", "expected YYYY-MM-DD"},
+		{"12/01/2025", "ambiguous date"},
+		{"01.12", "ambiguous date"},
+		{"-3", "ambiguous offset"},
+		{"+2m", "ambiguous unit"},
+		{"-2x", "unknown unit"},
+		{"t", "ambiguous weekday"},
+		{"last s", "ambiguous weekday"},
+		{"2025-W52", "range of days"},
+		{"2025-12", "range of days"},
+		{"last week", "range of days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr, fixedNow)
+			if err == nil {
+				t.Fatalf("Parse(%q) expected error", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %q, want it to mention %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		expr  string
+		start string
+		end   string
+	}{
+		{"yesterday", "2025-12-29", "2025-12-29"},
+		{"2025-W52", "2025-12-22", "2025-12-28"},
+		{"2026-W01", "2025-12-29", "2026-01-04"},
+		{"2020-W53", "2020-12-28", "2021-01-03"},
+		{"2025-12", "2025-12-01", "2025-12-31"},
+		{"2024-02", "2024-02-01", "2024-02-29"},
+		{"2025", "2025-01-01", "2025-12-31"},
+		{"this week", "2025-12-29", "2026-01-04"},
+		{"last week", "2025-12-22", "2025-12-28"},
+		{"this month", "2025-12-01", "2025-12-31"},
+		{"last month", "2025-11-01", "2025-11-30"},
+		{"last year", "2024-01-01", "2024-12-31"},
+		{"last 7d", "2025-12-24", "2025-12-30"},
+		{"last 2w", "2025-12-17", "2025-12-30"},
+		{"2025-12-01..yesterday", "2025-12-01", "2025-12-29"},
+		{"2025-11..2025-12", "2025-11-01", "2025-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseRange(tt.expr, fixedNow)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) error: %v", tt.expr, err)
+			}
+			if s := got.Start.Format("2006-01-02"); s != tt.start {
+				t.Errorf("ParseRange(%q).Start = %s, want %s", tt.expr, s, tt.start)
+			}
+			if e := got.End.Format("2006-01-02"); e != tt.end {
+				t.Errorf("ParseRange(%q).End = %s, want %s", tt.expr, e, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, expr := range []string{"2025-W54", "2025-13", "tomorrow..yesterday", "..today", "sometime"} {
+		if _, err := ParseRange(expr, fixedNow); err == nil {
+			t.Errorf("ParseRange(%q) expected error", expr)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	r, err := ParseRange("2025-W52", fixedNow)
+	if err != nil {
+		t.Fatalf("ParseRange error: %v", err)
+	}
+	if !r.Contains(time.Date(2025, 12, 28, 23, 0, 0, 0, time.UTC)) {
+		t.Error("range should contain its last day")
+	}
+	if r.Contains(time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Error("range should not contain the following Monday")
+	}
+	if r.Days() != 7 {
+		t.Errorf("Days() = %d, want 7", r.Days())
+	}
+}
+
+func TestParseEmptyRangeIsUnbounded(t *testing.T) {
+	r, err := ParseRange("", fixedNow)
+	if err != nil {
+		t.Fatalf("ParseRange error: %v", err)
+	}
+	if !r.IsZero() {
+		t.Errorf("expected zero range, got %v", r)
+	}
+}
@@ -1,29 +1,107 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
-type Todo struct {
-	Text string
-	Done bool
+// Answer is the response to one template question.
+type Answer struct {
+	ID       string // Template question ID, or its title when it has none
+	Question string // Question title, used as the section heading
+	Text     string
 }
 
 type JournalEntry struct {
-	Date      time.Time
+	Date      time.Time // First day of the period for reviews
+	Period    Period
 	Template  string
 	Mood      string
 	Energy    string
 	Highlight string
 	Todos     []Todo
 	Backlog   []Todo
-	Questions map[string]string // Question -> Answer
+	Answers   []Answer // In template order
+
+	// BacklogFrom is the date of the entry the backlog was carried over
+	// from, or zero if unknown.
+	BacklogFrom time.Time
+
+	// Summary is the generated overview of the period's daily entries in
+	// review entries.
+	Summary string
+
+	// Metrics holds the numeric answers (scale, number, boolean as 1/0)
+	// keyed by question ID, so they can be charted across entries.
+	Metrics map[string]float64
+
+	// Raw is the file content the entry was parsed from, if any. The
+	// markdown package uses it to preserve content it does not model.
+	Raw []byte
 }
 
 func NewJournalEntry(date time.Time, templateName string) *JournalEntry {
 	return &JournalEntry{
-		Date:      date,
-		Template:  templateName,
-		Todos:     make([]Todo, 0),
-		Backlog:   make([]Todo, 0),
-		Questions: make(map[string]string),
+		Date:     date,
+		Template: templateName,
+		Todos:    make([]Todo, 0),
+		Backlog:  make([]Todo, 0),
+		Answers:  make([]Answer, 0),
+		Metrics:  make(map[string]float64),
+	}
+}
+
+// Answer returns the answer stored under id.
+func (e *JournalEntry) Answer(id string) (string, bool) {
+	for _, a := range e.Answers {
+		if a.ID == id {
+			return a.Text, true
+		}
+	}
+	return "", false
+}
+
+// SetAnswer stores text as the answer to the question with the given id
+// and title, replacing an existing answer or appending a new one.
+func (e *JournalEntry) SetAnswer(id, question, text string) {
+	for i := range e.Answers {
+		if e.Answers[i].ID == id {
+			e.Answers[i].Question = question
+			e.Answers[i].Text = text
+			return
+		}
+	}
+	e.Answers = append(e.Answers, Answer{ID: id, Question: question, Text: text})
+}
+
+// RemoveAnswer drops the answer to question id, if any.
+func (e *JournalEntry) RemoveAnswer(id string) {
+	e.Answers = slices.DeleteFunc(e.Answers, func(a Answer) bool { return a.ID == id })
+}
+
+// SortAnswers orders answers by the given question IDs. Answers whose ID
+// is not listed (questions removed from the template) keep their relative
+// order and move to the end.
+func (e *JournalEntry) SortAnswers(ids []string) {
+	rank := make(map[string]int, len(ids))
+	for i, id := range ids {
+		if _, dup := rank[id]; !dup {
+			rank[id] = i
+		}
+	}
+	sorted := make([]Answer, 0, len(e.Answers))
+	for _, id := range ids {
+		for _, a := range e.Answers {
+			if a.ID == id {
+				sorted = append(sorted, a)
+				break
+			}
+		}
+	}
+	for _, a := range e.Answers {
+		if _, known := rank[a.ID]; !known {
+			sorted = append(sorted, a)
+		}
 	}
+	e.Answers = sorted
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -31,8 +32,8 @@ func TestNewJournalEntry(t *testing.T) {
 		t.Error("Backlog should be initialized, got nil")
 	}
 
-	if entry.Questions == nil {
-		t.Error("Questions should be initialized, got nil")
+	if entry.Answers == nil {
+		t.Error("Answers should be initialized, got nil")
 	}
 
 	if len(entry.Todos) != 0 {
@@ -43,8 +44,8 @@ func TestNewJournalEntry(t *testing.T) {
 		t.Errorf("Backlog should be empty, got %d items", len(entry.Backlog))
 	}
 
-	if len(entry.Questions) != 0 {
-		t.Errorf("Questions should be empty, got %d items", len(entry.Questions))
+	if len(entry.Answers) != 0 {
+		t.Errorf("Answers should be empty, got %d items", len(entry.Answers))
 	}
 }
 
@@ -56,18 +57,18 @@ func TestTodoStruct(t *testing.T) {
 	}{
 		{
 			name: "unchecked todo",
-			todo: Todo{Text: "Write tests", Done: false},
-			want: Todo{Text: "Write tests", Done: false},
+			todo: Todo{Text: "Write tests", Status: StatusOpen},
+			want: Todo{Text: "Write tests", Status: StatusOpen},
 		},
 		{
 			name: "checked todo",
-			todo: Todo{Text: "Build project", Done: true},
-			want: Todo{Text: "Build project", Done: true},
+			todo: Todo{Text: "Build project", Status: StatusDone},
+			want: Todo{Text: "Build project", Status: StatusDone},
 		},
 		{
 			name: "empty todo",
-			todo: Todo{Text: "", Done: false},
-			want: Todo{Text: "", Done: false},
+			todo: Todo{Text: "", Status: StatusOpen},
+			want: Todo{Text: "", Status: StatusOpen},
 		},
 	}
 
@@ -76,8 +77,8 @@ func TestTodoStruct(t *testing.T) {
 			if tt.todo.Text != tt.want.Text {
 				t.Errorf("Text = %v, want %v", tt.todo.Text, tt.want.Text)
 			}
-			if tt.todo.Done != tt.want.Done {
-				t.Errorf("Done = %v, want %v", tt.todo.Done, tt.want.Done)
+			if tt.todo.Status != tt.want.Status {
+				t.Errorf("Status = %v, want %v", tt.todo.Status, tt.want.Status)
 			}
 		})
 	}
@@ -110,8 +111,8 @@ func TestJournalEntryTodos(t *testing.T) {
 	entry := NewJournalEntry(date, "test-template")
 
 	// Add todos
-	entry.Todos = append(entry.Todos, Todo{Text: "Task 1", Done: false})
-	entry.Todos = append(entry.Todos, Todo{Text: "Task 2", Done: true})
+	entry.Todos = append(entry.Todos, Todo{Text: "Task 1", Status: StatusOpen})
+	entry.Todos = append(entry.Todos, Todo{Text: "Task 2", Status: StatusDone})
 
 	if len(entry.Todos) != 2 {
 		t.Errorf("Expected 2 todos, got %d", len(entry.Todos))
@@ -121,7 +122,7 @@ func TestJournalEntryTodos(t *testing.T) {
 		t.Errorf("First todo text = %v, want 'Task 1'", entry.Todos[0].Text)
 	}
 
-	if entry.Todos[1].Done != true {
+	if !entry.Todos[1].Done() {
 		t.Errorf("Second todo should be done")
 	}
 }
@@ -131,27 +132,150 @@ func TestJournalEntryBacklog(t *testing.T) {
 	entry := NewJournalEntry(date, "test-template")
 
 	// Add backlog items
-	entry.Backlog = append(entry.Backlog, Todo{Text: "Backlog 1", Done: false})
-	entry.Backlog = append(entry.Backlog, Todo{Text: "Backlog 2", Done: false})
+	entry.Backlog = append(entry.Backlog, Todo{Text: "Backlog 1", Status: StatusOpen})
+	entry.Backlog = append(entry.Backlog, Todo{Text: "Backlog 2", Status: StatusOpen})
 
 	if len(entry.Backlog) != 2 {
 		t.Errorf("Expected 2 backlog items, got %d", len(entry.Backlog))
 	}
 }
 
+func TestTodoProvenance(t *testing.T) {
+	date := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
+	entry := NewJournalEntry(date, "test-template")
+	carried := Todo{ID: "abc123", Text: "Renew passport", Created: date.AddDate(0, 0, -9)}.Carry(date)
+	entry.Todos = []Todo{carried, {Text: "Call mum"}, {Text: "Call mum"}, {Text: "Call mum", Status: StatusDone}}
+	entry.Backlog = []Todo{{ID: "abc123", Text: "Renew passport (old wording)"}, {Text: "Water plants"}}
+
+	entry.DedupeTodos()
+	if len(entry.Todos) != 2 || len(entry.Backlog) != 1 || entry.Backlog[0].Text != "Water plants" {
+		t.Fatalf("after dedupe: todos %v, backlog %v", entry.Todos, entry.Backlog)
+	}
+
+	entry.StampTodos()
+	ids := map[string]bool{}
+	for _, todo := range append(entry.Todos, entry.Backlog...) {
+		if todo.ID == "" || ids[todo.ID] || todo.Created.IsZero() {
+			t.Errorf("todo not stamped uniquely: %+v", todo)
+		}
+		ids[todo.ID] = true
+	}
+	if entry.Todos[0].ID != "abc123" || !entry.Todos[1].Created.Equal(date) {
+		t.Errorf("stamping changed existing metadata: %+v", entry.Todos)
+	}
+	if id := NewTodoID("Call mum", date); id != entry.Todos[1].ID {
+		t.Errorf("ID %q is not derived from text and date (%q)", entry.Todos[1].ID, id)
+	}
+
+	if carried.Carried != 1 || carried.Age(date) != 9 || !carried.Stale(date, 7) || carried.Stale(date, 10) {
+		t.Errorf("carried = %+v, age %d", carried, carried.Age(date))
+	}
+	if (Todo{Text: "new", Created: date}).Stale(date.AddDate(0, 1, 0), 7) {
+		t.Error("a todo that was never carried over is not stale")
+	}
+}
+
+func TestTodoTasksFields(t *testing.T) {
+	todo := Todo{Text: "Fix #42 in issue#7 #project/journal #work/urgent", Due: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)}
+	if got := strings.Join(todo.Tags(), ","); got != "project/journal,work/urgent" {
+		t.Errorf("Tags = %q", got)
+	}
+	if todo.Project() != "journal" {
+		t.Errorf("Project = %q", todo.Project())
+	}
+	if !todo.Overdue(time.Date(2025, 12, 30, 8, 0, 0, 0, time.Local)) || todo.Overdue(todo.Due) {
+		t.Error("Overdue should hold from the day after the due date")
+	}
+
+	for in, want := range map[string]Priority{"high": PriorityHigh, "⏬": PriorityLowest, " None ": PriorityNone, "🔺": PriorityHighest} {
+		if p, err := ParsePriority(in); err != nil || p != want {
+			t.Errorf("ParsePriority(%q) = %v, %v; want %v", in, p, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+	if PriorityHigh.Symbol() != "⏫" || PriorityNone.Symbol() != "" || PriorityMedium.String() != "medium" {
+		t.Error("unexpected priority symbol or name")
+	}
+}
+
 func TestJournalEntryQuestions(t *testing.T) {
 	date := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
 	entry := NewJournalEntry(date, "test-template")
 
 	// Add questions and answers
-	entry.Questions["How am I feeling?"] = "Great!"
-	entry.Questions["What did I learn?"] = "Testing is important"
+	entry.SetAnswer("feeling", "How am I feeling?", "Great!")
+	entry.SetAnswer("learned", "What did I learn?", "Testing is important")
+	entry.SetAnswer("feeling", "How am I feeling?", "Better")
 
-	if len(entry.Questions) != 2 {
-		t.Errorf("Expected 2 questions, got %d", len(entry.Questions))
+	if len(entry.Answers) != 2 {
+		t.Errorf("Expected 2 answers, got %d", len(entry.Answers))
 	}
 
-	if entry.Questions["How am I feeling?"] != "Great!" {
-		t.Errorf("Question answer mismatch")
+	if answer, ok := entry.Answer("feeling"); !ok || answer != "Better" {
+		t.Errorf("Question answer mismatch: %q", answer)
+	}
+}
+
+func TestJournalEntrySortAnswers(t *testing.T) {
+	entry := NewJournalEntry(time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC), "test-template")
+	entry.SetAnswer("removed", "A question no longer asked", "old")
+	entry.SetAnswer("b", "Second", "2")
+	entry.SetAnswer("orphan", "Another old question", "older")
+	entry.SetAnswer("a", "First", "1")
+
+	entry.SortAnswers([]string{"a", "b", "c"})
+
+	var got []string
+	for _, a := range entry.Answers {
+		got = append(got, a.ID)
+	}
+	want := []string{"a", "b", "removed", "orphan"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("SortAnswers order = %v, want %v", got, want)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	date := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC) // Tuesday of ISO week 2026-W01
+	tests := []struct {
+		period     Period
+		start, end string
+		name       string
+	}{
+		{PeriodDay, "2025-12-30", "2025-12-30", "2025-12-30"},
+		{PeriodWeek, "2025-12-29", "2026-01-04", "2026-W01"},
+		{PeriodMonth, "2025-12-01", "2025-12-31", "2025-12"},
+		{PeriodYear, "2025-01-01", "2025-12-31", "2025"},
+	}
+	for _, tt := range tests {
+		r := tt.period.Range(date)
+		if got := r.Start.Format("2006-01-02"); got != tt.start {
+			t.Errorf("%s: start = %s, want %s", tt.period, got, tt.start)
+		}
+		if got := r.End.Format("2006-01-02"); got != tt.end {
+			t.Errorf("%s: end = %s, want %s", tt.period, got, tt.end)
+		}
+		name := tt.period.Name(r.Start)
+		if name != tt.name {
+			t.Errorf("%s: name = %s, want %s", tt.period, name, tt.name)
+		}
+		p, start, ok := ParseEntryName(name, time.UTC)
+		if !ok || p != tt.period || !start.Equal(r.Start) {
+			t.Errorf("ParseEntryName(%q) = %v, %v, %v", name, p, start, ok)
+		}
+	}
+
+	for _, bad := range []string{"2025-W54", "2025-13", "notes"} {
+		if _, _, ok := ParseEntryName(bad, time.UTC); ok {
+			t.Errorf("ParseEntryName(%q) should fail", bad)
+		}
+	}
+	if p, err := ParsePeriod("Monthly"); err != nil || p != PeriodMonth {
+		t.Errorf("ParsePeriod(Monthly) = %v, %v", p, err)
+	}
+	if _, err := ParsePeriod("fortnight"); err == nil {
+		t.Errorf("ParsePeriod(fortnight) should fail")
 	}
 }
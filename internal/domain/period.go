@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+)
+
+// Period is the span of time an entry covers. Daily entries are the zero
+// value; reviews cover a week, month or year.
+type Period string
+
+const (
+	PeriodDay   Period = ""
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// Periods lists the review periods, shortest first.
+var Periods = []Period{PeriodWeek, PeriodMonth, PeriodYear}
+
+// ParsePeriod reads a period name such as "week" or "monthly". "day",
+// "daily" and "" are the daily period.
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "day", "daily":
+		return PeriodDay, nil
+	case "week", "weekly":
+		return PeriodWeek, nil
+	case "month", "monthly":
+		return PeriodMonth, nil
+	case "year", "yearly":
+		return PeriodYear, nil
+	}
+	return PeriodDay, fmt.Errorf("unknown period %q (use day, week, month or year)", s)
+}
+
+func (p Period) String() string {
+	if p == PeriodDay {
+		return "day"
+	}
+	return string(p)
+}
+
+// Start returns the first day of the period containing t. Weeks are ISO
+// weeks, starting on Monday.
+func (p Period) Start(t time.Time) time.Time {
+	d := dateexpr.Day(t)
+	switch p {
+	case PeriodWeek:
+		year, week := d.ISOWeek()
+		return dateexpr.ISOWeekStart(year, week, d.Location())
+	case PeriodMonth:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location())
+	case PeriodYear:
+		return time.Date(d.Year(), time.January, 1, 0, 0, 0, 0, d.Location())
+	}
+	return d
+}
+
+// Range returns the days of the period containing t.
+func (p Period) Range(t time.Time) dateexpr.Range {
+	start := p.Start(t)
+	var end time.Time
+	switch p {
+	case PeriodWeek:
+		end = start.AddDate(0, 0, 6)
+	case PeriodMonth:
+		end = start.AddDate(0, 1, -1)
+	case PeriodYear:
+		end = start.AddDate(1, 0, -1)
+	default:
+		end = start
+	}
+	return dateexpr.Range{Start: start, End: end}
+}
+
+// Name returns the file name, without extension, of the entry for the
+// period containing t: 2025-12-30, 2025-W01, 2025-12 or 2025.
+func (p Period) Name(t time.Time) string {
+	switch p {
+	case PeriodWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonth:
+		return t.Format("2006-01")
+	case PeriodYear:
+		return t.Format("2006")
+	}
+	return t.Format("2006-01-02")
+}
+
+// Title is the heading of a new entry for the period containing t.
+func (p Period) Title(t time.Time) string {
+	switch p {
+	case PeriodWeek:
+		return "Weekly Review – " + p.Name(t)
+	case PeriodMonth:
+		return "Monthly Review – " + t.Format("January 2006")
+	case PeriodYear:
+		return "Yearly Review – " + p.Name(t)
+	}
+	return "Daily Journal – " + p.Name(t)
+}
+
+// ParseEntryName reads the period and start date from an entry's file name
+// (without extension), the reverse of Name.
+func ParseEntryName(name string, loc *time.Location) (Period, time.Time, bool) {
+	if d, err := time.ParseInLocation("2006-01-02", name, loc); err == nil {
+		return PeriodDay, d, true
+	}
+	var year, n int
+	if _, err := fmt.Sscanf(name, "%4d-W%2d", &year, &n); err == nil && len(name) == 8 {
+		if start := dateexpr.ISOWeekStart(year, n, loc); PeriodWeek.Name(start) == name {
+			return PeriodWeek, start, true
+		}
+	}
+	if d, err := time.ParseInLocation("2006-01", name, loc); err == nil {
+		return PeriodMonth, d, true
+	}
+	if d, err := time.ParseInLocation("2006", name, loc); err == nil {
+		return PeriodYear, d, true
+	}
+	return PeriodDay, time.Time{}, false
+}
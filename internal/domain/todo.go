@@ -0,0 +1,303 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Todo is a task in the Todos or Backlog section of an entry. Besides its
+// text and state it records where it came from, so the same item can be
+// recognised in both sections and items that keep rolling over stand out.
+type Todo struct {
+	ID     string // Stable identifier; empty until the todo is first saved
+	Text   string
+	Status Status
+
+	Created   time.Time // Day the todo was first written down
+	CarriedOn time.Time // Last day it was carried over to a new entry
+	Carried   int       // Number of times it has been carried over
+
+	// Obsidian Tasks metadata. Tags stay in Text; see Tags and Project.
+	Priority   Priority
+	Due        time.Time
+	Recurrence string // Tasks recurrence rule, e.g. "every week"
+
+	// Extra holds the other Tasks fields of the line, such as "⏳
+	// 2025-12-30", verbatim and in order, so they survive edits.
+	Extra []string
+}
+
+// Status is the state of a todo, written as the character in its
+// checkbox. The zero value is an open todo.
+type Status int
+
+const (
+	StatusOpen       Status = iota // [ ]
+	StatusInProgress               // [/] Partly done
+	StatusDone                     // [x]
+	StatusCancelled                // [-] Dropped; not carried over
+	StatusDeferred                 // [>] Put off; carried over like an open todo
+)
+
+var statusMarks = [...]byte{' ', '/', 'x', '-', '>'}
+
+var statusNames = [...]string{"open", "in progress", "done", "cancelled", "deferred"}
+
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return strconv.Itoa(int(s))
+	}
+	return statusNames[s]
+}
+
+// Mark returns the checkbox character of the status.
+func (s Status) Mark() byte {
+	if s < 0 || int(s) >= len(statusMarks) {
+		return ' '
+	}
+	return statusMarks[s]
+}
+
+// StatusFromMark reads a checkbox character. "X" counts as done; other
+// characters are not recognised.
+func StatusFromMark(c byte) (Status, bool) {
+	if c == 'X' {
+		return StatusDone, true
+	}
+	for s, m := range statusMarks {
+		if m == c {
+			return Status(s), true
+		}
+	}
+	return StatusOpen, false
+}
+
+// ParseStatus reads a status name such as "done" or "in-progress".
+func ParseStatus(s string) (Status, error) {
+	s = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", " ")
+	for i, name := range statusNames {
+		if name == s {
+			return Status(i), nil
+		}
+	}
+	return StatusOpen, fmt.Errorf("unknown status %q (use open, in-progress, done, cancelled or deferred)", s)
+}
+
+// Done reports whether the todo is completed.
+func (t Todo) Done() bool {
+	return t.Status == StatusDone
+}
+
+// Closed reports whether the todo needs no more work: it is done or
+// cancelled. Open todos are carried over to the next entry.
+func (t Todo) Closed() bool {
+	return t.Status == StatusDone || t.Status == StatusCancelled
+}
+
+// Priority is an Obsidian Tasks priority. The zero value is no priority,
+// which Tasks sorts between medium and low.
+type Priority int
+
+const (
+	PriorityLowest Priority = iota - 2
+	PriorityLow
+	PriorityNone
+	PriorityMedium
+	PriorityHigh
+	PriorityHighest
+)
+
+var priorityNames = map[Priority]string{
+	PriorityLowest:  "lowest",
+	PriorityLow:     "low",
+	PriorityNone:    "none",
+	PriorityMedium:  "medium",
+	PriorityHigh:    "high",
+	PriorityHighest: "highest",
+}
+
+var prioritySymbols = map[Priority]string{
+	PriorityLowest:  "⏬",
+	PriorityLow:     "🔽",
+	PriorityMedium:  "🔼",
+	PriorityHigh:    "⏫",
+	PriorityHighest: "🔺",
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return strconv.Itoa(int(p))
+}
+
+// Symbol returns the Tasks emoji of the priority, "" for none.
+func (p Priority) Symbol() string {
+	return prioritySymbols[p]
+}
+
+// PriorityFromSymbol reads a Tasks priority emoji.
+func PriorityFromSymbol(s string) (Priority, bool) {
+	s = strings.TrimSuffix(s, "\ufe0f")
+	for p, sym := range prioritySymbols {
+		if sym == s {
+			return p, true
+		}
+	}
+	return PriorityNone, false
+}
+
+// ParsePriority reads a priority name such as "high", or its emoji.
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for p, name := range priorityNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	if p, ok := PriorityFromSymbol(s); ok {
+		return p, nil
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q (use highest, high, medium, none, low or lowest)", s)
+}
+
+// Tags returns the #tags in the todo's text, without the "#".
+func (t Todo) Tags() []string {
+	var tags []string
+	for _, m := range tagPattern.FindAllStringSubmatch(t.Text, -1) {
+		tags = append(tags, m[1])
+	}
+	return tags
+}
+
+// Project returns the project named by a #project/<name> tag, if any.
+func (t Todo) Project() string {
+	for _, tag := range t.Tags() {
+		if name, ok := strings.CutPrefix(tag, "project/"); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// tagPattern matches Obsidian tags: "#" and a name that is not only digits.
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+
+// Overdue reports whether the open todo was due before date.
+func (t Todo) Overdue(date time.Time) bool {
+	return !t.Closed() && !t.Due.IsZero() && daysBetween(t.Due, date) > 0
+}
+
+// Equal reports whether t and u are the same todo in the same state.
+// Dates are compared by day.
+func (t Todo) Equal(u Todo) bool {
+	return t.ID == u.ID && t.Text == u.Text && t.Status == u.Status && t.Carried == u.Carried &&
+		sameDay(t.Created, u.Created) && sameDay(t.CarriedOn, u.CarriedOn) &&
+		t.Priority == u.Priority && sameDay(t.Due, u.Due) && t.Recurrence == u.Recurrence &&
+		slices.Equal(t.Extra, u.Extra)
+}
+
+// Same reports whether t and u are the same item, possibly in a different
+// state: they share an ID, or, when either has none, their text.
+func (t Todo) Same(u Todo) bool {
+	if t.ID != "" && u.ID != "" {
+		return t.ID == u.ID
+	}
+	return t.Text == u.Text
+}
+
+// Carry returns the todo as carried over to the entry for date.
+func (t Todo) Carry(date time.Time) Todo {
+	t.Carried++
+	t.CarriedOn = date
+	return t
+}
+
+// Age returns the number of days from the todo's creation to date, 0 when
+// the creation date is unknown.
+func (t Todo) Age(date time.Time) int {
+	if t.Created.IsZero() {
+		return 0
+	}
+	return daysBetween(t.Created, date)
+}
+
+// Stale reports whether the open todo has been carried over and is at
+// least days days old on date.
+func (t Todo) Stale(date time.Time, days int) bool {
+	return !t.Closed() && t.Carried > 0 && days > 0 && t.Age(date) >= days
+}
+
+// NewTodoID derives a short ID from the todo's text and creation date.
+// IDs only need to be unique within the todos that can meet, so a hash is
+// enough; StampTodos resolves the rare clash.
+func NewTodoID(text string, created time.Time) string {
+	sum := sha1.Sum([]byte(created.Format("2006-01-02") + "\n" + text))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(sum[:])[:6])
+}
+
+// StampTodos gives every todo of the entry without an ID a unique one and
+// dates those without a creation date to the entry.
+func (e *JournalEntry) StampTodos() {
+	seen := map[string]bool{}
+	for _, list := range [][]Todo{e.Todos, e.Backlog} {
+		for _, t := range list {
+			seen[t.ID] = true
+		}
+	}
+	for _, list := range [][]Todo{e.Todos, e.Backlog} {
+		for i := range list {
+			t := &list[i]
+			if t.Created.IsZero() {
+				t.Created = e.Date
+			}
+			if t.ID != "" {
+				continue
+			}
+			id := NewTodoID(t.Text, t.Created)
+			for n := 2; seen[id]; n++ {
+				id = NewTodoID(t.Text+"\n"+strconv.Itoa(n), t.Created)
+			}
+			t.ID = id
+			seen[id] = true
+		}
+	}
+}
+
+// DedupeTodos drops repeated todos: later copies of an item in Todos or in
+// Backlog, and Backlog items that are also in Todos.
+func (e *JournalEntry) DedupeTodos() {
+	e.Todos = dedupe(e.Todos, nil)
+	e.Backlog = dedupe(e.Backlog, e.Todos)
+}
+
+func dedupe(todos, exclude []Todo) []Todo {
+	out := make([]Todo, 0, len(todos))
+	for _, t := range todos {
+		dup := func(u Todo) bool { return u.Same(t) }
+		if !slices.ContainsFunc(out, dup) && !slices.ContainsFunc(exclude, dup) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func sameDay(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() == b.IsZero()
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// daysBetween counts calendar days from a to b, ignoring time of day and
+// daylight saving changes.
+func daysBetween(a, b time.Time) int {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(b).Sub(day(a)).Hours() / 24)
+}
@@ -1,10 +1,50 @@
 package fs
 
 import (
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 )
 
+// FS is the set of filesystem operations the application relies on. OS is
+// the production implementation; MemFS keeps files in memory for tests.
+type FS interface {
+	// ReadFile reads data from a file.
+	ReadFile(path string) ([]byte, error)
+	// WriteFile writes data to a file, creating the directory if it doesn't exist.
+	WriteFile(path string, data []byte) error
+	// MkdirAll ensures that the directory exists, creating it if necessary.
+	MkdirAll(path string) error
+	// ReadDir lists a directory sorted by file name.
+	ReadDir(path string) ([]iofs.DirEntry, error)
+	// Stat describes a file.
+	Stat(path string) (iofs.FileInfo, error)
+	// Exists checks if a file exists.
+	Exists(path string) bool
+	// Rename moves a file, creating the target directory if needed.
+	Rename(oldPath, newPath string) error
+	// Remove deletes a file or empty directory.
+	Remove(path string) error
+}
+
+// OS implements FS on top of the real filesystem.
+type OS struct{}
+
+func (OS) ReadFile(path string) ([]byte, error)         { return ReadFile(path) }
+func (OS) WriteFile(path string, data []byte) error     { return WriteFile(path, data) }
+func (OS) MkdirAll(path string) error                   { return EnsureDir(path) }
+func (OS) ReadDir(path string) ([]iofs.DirEntry, error) { return os.ReadDir(path) }
+func (OS) Stat(path string) (iofs.FileInfo, error)      { return os.Stat(path) }
+func (OS) Exists(path string) bool                      { return Exists(path) }
+func (OS) Remove(path string) error                     { return os.Remove(path) }
+
+func (OS) Rename(oldPath, newPath string) error {
+	if err := EnsureDir(filepath.Dir(newPath)); err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
 // EnsureDir ensures that the directory exists, creating it if necessary.
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0755)
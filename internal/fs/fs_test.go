@@ -40,3 +40,48 @@ func TestEnsureWriteReadExists(t *testing.T) {
     // cleanup test file
     os.Remove(p)
 }
+
+func TestMemFS(t *testing.T) {
+    m := NewMemFS()
+    p := filepath.Join("vault", "Journal", "2025-12-30.md")
+    if err := m.WriteFile(p, []byte("entry")); err != nil {
+        t.Fatalf("WriteFile failed: %v", err)
+    }
+
+    if !m.Exists(p) || !m.Exists(filepath.Join("vault", "Journal")) {
+        t.Fatalf("file and parent directory should exist")
+    }
+
+    first, err := m.Stat(p)
+    if err != nil {
+        t.Fatalf("Stat failed: %v", err)
+    }
+    if err := m.WriteFile(p, []byte("updated")); err != nil {
+        t.Fatalf("WriteFile failed: %v", err)
+    }
+    second, _ := m.Stat(p)
+    if !second.ModTime().After(first.ModTime()) {
+        t.Fatalf("rewriting a file should advance its mod time")
+    }
+
+    entries, err := m.ReadDir("vault")
+    if err != nil || len(entries) != 1 || !entries[0].IsDir() || entries[0].Name() != "Journal" {
+        t.Fatalf("unexpected ReadDir result: %v, %v", entries, err)
+    }
+
+    moved := filepath.Join("vault", "2025", "2025-12-30.md")
+    if err := m.Rename(p, moved); err != nil {
+        t.Fatalf("Rename failed: %v", err)
+    }
+    got, err := m.ReadFile(moved)
+    if err != nil || string(got) != "updated" {
+        t.Fatalf("ReadFile after rename = %q, %v", got, err)
+    }
+    if m.Exists(p) {
+        t.Fatalf("old path should be gone after rename")
+    }
+
+    if _, err := m.ReadFile(p); !os.IsNotExist(err) {
+        t.Fatalf("expected not-exist error, got %v", err)
+    }
+}
@@ -0,0 +1,183 @@
+package fs
+
+import (
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory FS for tests. Paths are cleaned before use, so
+// "a/b/../c" and "a/c" refer to the same file. The zero value is not usable;
+// create one with NewMemFS.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+	dirs  map[string]bool
+	tick  time.Time
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files: make(map[string]*memFile),
+		dirs:  map[string]bool{string(filepath.Separator): true, ".": true},
+		tick:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// nextModTime returns a strictly increasing modification time so that
+// every write is observable through Stat.
+func (m *MemFS) nextModTime() time.Time {
+	m.tick = m.tick.Add(time.Second)
+	return m.tick
+}
+
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &iofs.PathError{Op: "open", Path: path, Err: iofs.ErrNotExist}
+	}
+	return append([]byte(nil), f.data...), nil
+}
+
+func (m *MemFS) WriteFile(path string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := filepath.Clean(path)
+	if m.dirs[p] {
+		return &iofs.PathError{Op: "open", Path: path, Err: iofs.ErrExist}
+	}
+	m.mkdirAll(filepath.Dir(p))
+	m.files[p] = &memFile{data: append([]byte(nil), data...), modTime: m.nextModTime()}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mkdirAll(filepath.Clean(path))
+	return nil
+}
+
+func (m *MemFS) mkdirAll(p string) {
+	for !m.dirs[p] {
+		m.dirs[p] = true
+		parent := filepath.Dir(p)
+		if parent == p {
+			return
+		}
+		p = parent
+	}
+}
+
+func (m *MemFS) ReadDir(path string) ([]iofs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := filepath.Clean(path)
+	if !m.dirs[p] {
+		return nil, &iofs.PathError{Op: "open", Path: path, Err: iofs.ErrNotExist}
+	}
+
+	var entries []iofs.DirEntry
+	for d := range m.dirs {
+		if d != p && filepath.Dir(d) == p {
+			entries = append(entries, iofs.FileInfoToDirEntry(memInfo{name: filepath.Base(d), dir: true}))
+		}
+	}
+	for f, mf := range m.files {
+		if filepath.Dir(f) == p {
+			entries = append(entries, iofs.FileInfoToDirEntry(memInfo{name: filepath.Base(f), size: int64(len(mf.data)), modTime: mf.modTime}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *MemFS) Stat(path string) (iofs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := filepath.Clean(path)
+	if f, ok := m.files[p]; ok {
+		return memInfo{name: filepath.Base(p), size: int64(len(f.data)), modTime: f.modTime}, nil
+	}
+	if m.dirs[p] {
+		return memInfo{name: filepath.Base(p), dir: true}, nil
+	}
+	return nil, &iofs.PathError{Op: "stat", Path: path, Err: iofs.ErrNotExist}
+}
+
+func (m *MemFS) Exists(path string) bool {
+	_, err := m.Stat(path)
+	return err == nil
+}
+
+func (m *MemFS) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	src, dst := filepath.Clean(oldPath), filepath.Clean(newPath)
+	f, ok := m.files[src]
+	if !ok {
+		return &iofs.PathError{Op: "rename", Path: oldPath, Err: iofs.ErrNotExist}
+	}
+	m.mkdirAll(filepath.Dir(dst))
+	delete(m.files, src)
+	m.files[dst] = f
+	return nil
+}
+
+func (m *MemFS) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := filepath.Clean(path)
+	if _, ok := m.files[p]; ok {
+		delete(m.files, p)
+		return nil
+	}
+	if m.dirs[p] {
+		prefix := p + string(filepath.Separator)
+		for other := range m.dirs {
+			if strings.HasPrefix(other, prefix) {
+				return &iofs.PathError{Op: "remove", Path: path, Err: iofs.ErrExist}
+			}
+		}
+		for f := range m.files {
+			if strings.HasPrefix(f, prefix) {
+				return &iofs.PathError{Op: "remove", Path: path, Err: iofs.ErrExist}
+			}
+		}
+		delete(m.dirs, p)
+		return nil
+	}
+	return &iofs.PathError{Op: "remove", Path: path, Err: iofs.ErrNotExist}
+}
+
+// memInfo implements iofs.FileInfo for MemFS entries.
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() iofs.FileMode {
+	if i.dir {
+		return iofs.ModeDir | 0755
+	}
+	return 0644
+}
@@ -0,0 +1,293 @@
+// Package index keeps the parsed entries of a journal and an inverted
+// index of their words in a cache file, so commands that read every entry
+// only parse the files that changed since the last run.
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	iofs "io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
+	"journal-cli/internal/markdown"
+)
+
+// version is stored in the index file; files written with another
+// version are ignored and rebuilt. Bump it when Doc or the parsed entry
+// changes shape.
+const version = 2
+
+// Doc is an indexed Markdown file of the journal.
+type Doc struct {
+	ID      int
+	Path    string // Relative to the journal directory
+	ModTime time.Time
+	Size    int64
+
+	Entry *domain.JournalEntry // Nil when the file could not be parsed
+	Err   string               // Why it could not be parsed
+	Note  bool                 // Not an entry: the file has no frontmatter date
+}
+
+// Index is the index of the Markdown files in a journal directory.
+type Index struct {
+	dir     string
+	docs    map[int]*Doc
+	byPath  map[string]int
+	terms   map[string][]int // Word -> sorted IDs of the docs containing it
+	nextID  int
+	changed bool
+}
+
+// New returns an empty index of dir.
+func New(dir string) *Index {
+	return &Index{dir: dir, docs: map[int]*Doc{}, byPath: map[string]int{}, terms: map[string][]int{}}
+}
+
+// file is the stored form of an Index.
+type file struct {
+	Version int
+	Dir     string
+	Docs    []*Doc
+	Terms   map[string][]int
+	NextID  int
+}
+
+// Load reads the index of dir stored at path. A missing or unreadable
+// file, or one written for another directory or by another version,
+// gives an empty index to be filled by Update.
+func Load(fsys fs.FS, path, dir string) (*Index, error) {
+	data, err := fsys.ReadFile(path)
+	if errors.Is(err, iofs.ErrNotExist) {
+		return New(dir), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&f); err != nil || f.Version != version || f.Dir != dir {
+		x := New(dir)
+		x.changed = true
+		return x, nil
+	}
+	x := New(dir)
+	x.terms, x.nextID = f.Terms, f.NextID
+	if x.terms == nil {
+		x.terms = map[string][]int{}
+	}
+	for _, d := range f.Docs {
+		x.docs[d.ID] = d
+		x.byPath[d.Path] = d.ID
+	}
+	return x, nil
+}
+
+// Save writes the index to path, replacing the file in one step.
+func (x *Index) Save(fsys fs.FS, path string) error {
+	f := file{Version: version, Dir: x.dir, Docs: x.Docs(), Terms: x.terms, NextID: x.nextID}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := fsys.WriteFile(tmp, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := fsys.Rename(tmp, path); err != nil {
+		return err
+	}
+	x.changed = false
+	return nil
+}
+
+// Dir returns the journal directory the index covers.
+func (x *Index) Dir() string {
+	return x.dir
+}
+
+// Changed reports whether the index differs from the file it was loaded
+// from.
+func (x *Index) Changed() bool {
+	return x.changed
+}
+
+// Docs returns the indexed files sorted by path.
+func (x *Index) Docs() []*Doc {
+	docs := make([]*Doc, 0, len(x.docs))
+	for _, d := range x.docs {
+		docs = append(docs, d)
+	}
+	slices.SortFunc(docs, func(a, b *Doc) int { return strings.Compare(a.Path, b.Path) })
+	return docs
+}
+
+// Daily is a daily entry of the index.
+type Daily struct {
+	Date  time.Time // From the file name
+	Path  string
+	Entry *domain.JournalEntry // Nil when the file could not be parsed
+}
+
+// Daily returns the daily entries l names, in date order.
+func (x *Index) Daily(l layout.Layout) []Daily {
+	var entries []Daily
+	for _, d := range x.Docs() {
+		date, ok := l.Match(d.Path, time.Local)
+		if !ok {
+			continue
+		}
+		entries = append(entries, Daily{Date: date, Path: filepath.Join(x.dir, d.Path), Entry: d.Entry})
+	}
+	slices.SortStableFunc(entries, func(a, b Daily) int { return a.Date.Compare(b.Date) })
+	return entries
+}
+
+// Update brings the index in line with the files in its directory: files
+// whose modification time or size changed are parsed again, new ones are
+// added and deleted ones dropped.
+func (x *Index) Update(fsys fs.FS) error {
+	seen := map[string]bool{}
+	if fsys.Exists(x.dir) {
+		err := layout.Walk(fsys, x.dir, func(name string) error {
+			seen[name] = true
+			return x.refresh(fsys, name)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for path, id := range x.byPath {
+		if !seen[path] {
+			x.remove(id)
+		}
+	}
+	return nil
+}
+
+// Refresh updates the index for the file at path, in the index's
+// directory, after it was written or removed.
+func (x *Index) Refresh(fsys fs.FS, path string) error {
+	rel, err := filepath.Rel(x.dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil // Not in the journal
+	}
+	if !fsys.Exists(path) {
+		if id, ok := x.byPath[rel]; ok {
+			x.remove(id)
+		}
+		return nil
+	}
+	return x.refresh(fsys, rel)
+}
+
+// refresh parses the file at rel again unless its index entry is current.
+func (x *Index) refresh(fsys fs.FS, rel string) error {
+	path := filepath.Join(x.dir, rel)
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return err
+	}
+	if id, ok := x.byPath[rel]; ok {
+		d := x.docs[id]
+		if d.ModTime.Equal(info.ModTime()) && d.Size == info.Size() {
+			return nil
+		}
+		x.remove(id)
+	}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	d := &Doc{ID: x.nextID, Path: rel, ModTime: info.ModTime(), Size: info.Size()}
+	x.nextID++
+	if entry, err := markdown.ParseMarkdown(data); err != nil {
+		d.Err = err.Error()
+		d.Note = errors.Is(err, markdown.ErrNotEntry)
+	} else {
+		entry.Raw = nil
+		d.Entry = entry
+	}
+	x.docs[d.ID] = d
+	x.byPath[rel] = d.ID
+	for _, w := range docWords(d) {
+		x.terms[w] = append(x.terms[w], d.ID) // IDs only grow, so this stays sorted
+	}
+	x.changed = true
+	return nil
+}
+
+func (x *Index) remove(id int) {
+	d := x.docs[id]
+	for _, w := range docWords(d) {
+		ids := x.terms[w]
+		if i, ok := slices.BinarySearch(ids, id); ok {
+			ids = slices.Delete(ids, i, i+1)
+		}
+		if len(ids) == 0 {
+			delete(x.terms, w)
+		} else {
+			x.terms[w] = ids
+		}
+	}
+	delete(x.docs, id)
+	delete(x.byPath, d.Path)
+	x.changed = true
+}
+
+// Containing returns the docs with an indexed word that contains the word
+// w, which must be one of Words' results. It is a superset of the docs
+// whose text contains w, ignoring case.
+func (x *Index) Containing(w string) map[*Doc]bool {
+	out := map[*Doc]bool{}
+	for term, ids := range x.terms {
+		if !strings.Contains(term, w) {
+			continue
+		}
+		for _, id := range ids {
+			out[x.docs[id]] = true
+		}
+	}
+	return out
+}
+
+// Words splits s into the lowercase words the index is made of: runs of
+// letters and digits.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Text returns the text of e that is indexed and searched: the summary,
+// mood, energy, highlight, todos and answers.
+func Text(e *domain.JournalEntry) []string {
+	text := []string{e.Summary, e.Mood, e.Energy, e.Highlight}
+	for _, t := range slices.Concat(e.Todos, e.Backlog) {
+		text = append(text, t.Text)
+	}
+	for _, a := range e.Answers {
+		text = append(text, a.Text)
+	}
+	return text
+}
+
+// docWords returns the distinct words of d's entry.
+func docWords(d *Doc) []string {
+	if d.Entry == nil {
+		return nil
+	}
+	var words []string
+	for _, s := range Text(d.Entry) {
+		words = append(words, Words(s)...)
+	}
+	slices.Sort(words)
+	return slices.Compact(words)
+}
@@ -0,0 +1,144 @@
+package index
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
+)
+
+const day1 = "---\ndate: 2025-12-01\nmood: tired\n---\n\n" +
+	"## ✅ Todos – Today\n- [ ] Plan the database migration\n"
+
+func paths(docs map[*Doc]bool) []string {
+	var out []string
+	for d := range docs {
+		out = append(out, d.Path)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestUpdate(t *testing.T) {
+	mem := fs.NewMemFS()
+	write := func(name, content string) {
+		t.Helper()
+		if err := mem.WriteFile(filepath.Join("/j", name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("2025-12-01.md", day1)
+	write("2025-12-02.md", "---\ndate: 2025-12-02\nhighlight: Migration went live\n---\n")
+	write("notes.md", "no frontmatter")
+
+	x := New("/j")
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	if !x.Changed() || len(x.Docs()) != 3 {
+		t.Fatalf("Update: changed %v, %d docs", x.Changed(), len(x.Docs()))
+	}
+	if got := paths(x.Containing("migrat")); !slices.Equal(got, []string{"2025-12-01.md", "2025-12-02.md"}) {
+		t.Errorf("Containing(migrat) = %v", got)
+	}
+	if got := paths(x.Containing("tired")); !slices.Equal(got, []string{"2025-12-01.md"}) {
+		t.Errorf("Containing(tired) = %v", got)
+	}
+	daily := x.Daily(layout.Layout{})
+	if len(daily) != 2 || daily[0].Path != filepath.Join("/j", "2025-12-01.md") || daily[0].Entry.Mood != "tired" {
+		t.Errorf("Daily = %+v", daily)
+	}
+
+	// A saved index loads unchanged and is current with the files.
+	if err := x.Save(mem, "/cache/index.gob"); err != nil {
+		t.Fatal(err)
+	}
+	x, err := Load(mem, "/cache/index.gob", "/j")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	if x.Changed() || len(x.Docs()) != 3 {
+		t.Errorf("Update after Load: changed %v, %d docs", x.Changed(), len(x.Docs()))
+	}
+	if d := x.Docs()[2]; d.Path != "notes.md" || d.Entry != nil || d.Err == "" {
+		t.Errorf("unparsable doc = %+v", d)
+	}
+
+	// Only changed files are indexed again.
+	write("2025-12-01.md", "---\ndate: 2025-12-01\nmood: rested\n---\n")
+	if err := mem.Remove(filepath.Join("/j", "2025-12-02.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	if !x.Changed() || len(x.Docs()) != 2 {
+		t.Errorf("Update after edits: changed %v, %d docs", x.Changed(), len(x.Docs()))
+	}
+	if got := paths(x.Containing("migration")); len(got) != 0 {
+		t.Errorf("Containing(migration) after edits = %v", got)
+	}
+	if got := paths(x.Containing("rested")); !slices.Equal(got, []string{"2025-12-01.md"}) {
+		t.Errorf("Containing(rested) = %v", got)
+	}
+
+	// Refresh picks up a single written or removed file.
+	write("2025-12-03.md", "---\ndate: 2025-12-03\nmood: rested\n---\n")
+	if err := x.Refresh(mem, filepath.Join("/j", "2025-12-03.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Remove(filepath.Join("/j", "2025-12-01.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Refresh(mem, filepath.Join("/j", "2025-12-01.md")); err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(x.Containing("rested")); !slices.Equal(got, []string{"2025-12-03.md"}) {
+		t.Errorf("Containing(rested) after Refresh = %v", got)
+	}
+}
+
+func TestLoadStale(t *testing.T) {
+	mem := fs.NewMemFS()
+	if err := mem.WriteFile(filepath.Join("/j", "2025-12-01.md"), []byte(day1)); err != nil {
+		t.Fatal(err)
+	}
+	x := New("/j")
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Save(mem, "/cache/index.gob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An index of another directory, or a damaged one, starts over.
+	other, err := Load(mem, "/cache/index.gob", "/other")
+	if err != nil || !other.Changed() || len(other.Docs()) != 0 || other.Dir() != "/other" {
+		t.Errorf("Load(/other) = %d docs, changed %v, %v", len(other.Docs()), other.Changed(), err)
+	}
+	if err := mem.WriteFile("/cache/index.gob", []byte("garbage")); err != nil {
+		t.Fatal(err)
+	}
+	damaged, err := Load(mem, "/cache/index.gob", "/j")
+	if err != nil || !damaged.Changed() || len(damaged.Docs()) != 0 {
+		t.Errorf("Load(damaged) = %d docs, changed %v, %v", len(damaged.Docs()), damaged.Changed(), err)
+	}
+
+	x, err = Load(mem, "/cache/missing.gob", "/j")
+	if err != nil || x.Changed() || len(x.Docs()) != 0 {
+		t.Errorf("Load(missing) = %d docs, changed %v, %v", len(x.Docs()), x.Changed(), err)
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Postgres locks whole tables on ALTER; café-2025 #work/deep")
+	want := []string{"postgres", "locks", "whole", "tables", "on", "alter", "café", "2025", "work", "deep"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Words = %q, want %q", got, want)
+	}
+}
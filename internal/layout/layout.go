@@ -0,0 +1,290 @@
+// Package layout maps the dates of daily entries to their files below the
+// journal folder, following a pattern of date tokens such as
+// "YYYY/MM/YYYY-MM-DD" (Journal/2025/12/2025-12-30.md).
+package layout
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal-cli/internal/fs"
+)
+
+// Default is the flat layout: one YYYY-MM-DD.md file per day directly in
+// the journal folder.
+const Default = "YYYY-MM-DD"
+
+// token is a date field a pattern can contain. The names follow the
+// moment.js formats used by Obsidian's daily notes.
+type token struct {
+	name   string
+	re     string
+	format func(time.Time) string
+}
+
+// tokens is ordered so that longer names match first.
+var tokens = []token{
+	{"YYYY", `\d{4}`, func(t time.Time) string { return t.Format("2006") }},
+	{"GGGG", `\d{4}`, func(t time.Time) string { y, _ := t.ISOWeek(); return strconv.Itoa(y) }},
+	{"MMMM", names(func(m int) string { return time.Month(m).String() }, 1, 12), func(t time.Time) string { return t.Format("January") }},
+	{"dddd", names(func(d int) string { return time.Weekday(d).String() }, 0, 6), func(t time.Time) string { return t.Format("Monday") }},
+	{"DDDD", `\d{3}`, func(t time.Time) string { return fmt.Sprintf("%03d", t.YearDay()) }},
+	{"MMM", names(func(m int) string { return time.Month(m).String()[:3] }, 1, 12), func(t time.Time) string { return t.Format("Jan") }},
+	{"ddd", names(func(d int) string { return time.Weekday(d).String()[:3] }, 0, 6), func(t time.Time) string { return t.Format("Mon") }},
+	{"YY", `\d{2}`, func(t time.Time) string { return t.Format("06") }},
+	{"MM", `\d{2}`, func(t time.Time) string { return t.Format("01") }},
+	{"DD", `\d{2}`, func(t time.Time) string { return t.Format("02") }},
+	{"WW", `\d{2}`, func(t time.Time) string { _, w := t.ISOWeek(); return fmt.Sprintf("%02d", w) }},
+	{"M", `\d{1,2}`, func(t time.Time) string { return t.Format("1") }},
+	{"D", `\d{1,2}`, func(t time.Time) string { return t.Format("2") }},
+	{"W", `\d{1,2}`, func(t time.Time) string { _, w := t.ISOWeek(); return strconv.Itoa(w) }},
+	{"Q", `[1-4]`, func(t time.Time) string { return strconv.Itoa((int(t.Month())-1)/3 + 1) }},
+}
+
+func names(name func(int) string, from, to int) string {
+	var alts []string
+	for i := from; i <= to; i++ {
+		alts = append(alts, name(i))
+	}
+	return strings.Join(alts, "|")
+}
+
+// part is a token or a run of literal text.
+type part struct {
+	tok     *token
+	literal string
+}
+
+// Layout is a parsed path pattern. The zero Layout is the Default one.
+type Layout struct {
+	pattern string
+	parts   []part
+	re      *regexp.Regexp
+}
+
+var defaultLayout = mustParse(Default)
+
+func mustParse(pattern string) Layout {
+	l, err := Parse(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Parse reads a path pattern. "/" separates folders, the tokens in the
+// tokens table stand for parts of the date and anything else is copied
+// as is; text in square brackets is never read as a token, so
+// "[Daily]/YYYY-MM-DD" puts entries in a Daily folder. The pattern must
+// name the day, with YYYY (or YY) and either MM and DD or DDDD. The ".md"
+// extension is added to the path and may be left out of the pattern.
+func Parse(pattern string) (Layout, error) {
+	l := Layout{pattern: pattern}
+	var re strings.Builder
+	re.WriteString("^")
+	s := strings.TrimSuffix(pattern, ".md")
+	for s != "" {
+		if s[0] == '[' {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return Layout{}, fmt.Errorf("path pattern %q: unclosed [", pattern)
+			}
+			l.literal(&re, s[1:end])
+			s = s[end+1:]
+			continue
+		}
+		i := slices.IndexFunc(tokens, func(t token) bool { return strings.HasPrefix(s, t.name) })
+		if i < 0 {
+			l.literal(&re, s[:1])
+			s = s[1:]
+			continue
+		}
+		l.parts = append(l.parts, part{tok: &tokens[i]})
+		fmt.Fprintf(&re, "(%s)", tokens[i].re)
+		s = s[len(tokens[i].name):]
+	}
+	re.WriteString("$")
+
+	has := func(names ...string) bool {
+		return slices.ContainsFunc(l.parts, func(p part) bool { return p.tok != nil && slices.Contains(names, p.tok.name) })
+	}
+	if !has("YYYY", "YY") || !(has("MM", "M", "MMM", "MMMM") && has("DD", "D") || has("DDDD")) {
+		return Layout{}, fmt.Errorf("path pattern %q does not name the day: it needs YYYY with MM and DD, or DDDD", pattern)
+	}
+	if slices.ContainsFunc(strings.Split(l.format(time.Now()), "/"), func(elem string) bool {
+		return elem == "" || elem == "." || elem == ".."
+	}) {
+		return Layout{}, fmt.Errorf("path pattern %q must be a relative path inside the journal folder", pattern)
+	}
+	l.re = regexp.MustCompile(re.String())
+	return l, nil
+}
+
+// literal appends text to the pattern, merging it with the literal before.
+func (l *Layout) literal(re *strings.Builder, text string) {
+	re.WriteString(regexp.QuoteMeta(text))
+	if n := len(l.parts); n > 0 && l.parts[n-1].tok == nil {
+		l.parts[n-1].literal += text
+		return
+	}
+	l.parts = append(l.parts, part{literal: text})
+}
+
+func (l Layout) orDefault() Layout {
+	if l.re == nil {
+		return defaultLayout
+	}
+	return l
+}
+
+// String returns the pattern the layout was parsed from.
+func (l Layout) String() string {
+	return l.orDefault().pattern
+}
+
+// format returns the slash-separated path of date's entry, without
+// extension.
+func (l Layout) format(date time.Time) string {
+	var b strings.Builder
+	for _, p := range l.parts {
+		if p.tok != nil {
+			b.WriteString(p.tok.format(date))
+		} else {
+			b.WriteString(p.literal)
+		}
+	}
+	return b.String()
+}
+
+// Name returns the path of date's entry relative to the journal folder.
+func (l Layout) Name(date time.Time) string {
+	return filepath.FromSlash(l.orDefault().format(date)) + ".md"
+}
+
+// Path returns the path of date's entry in the journal folder dir.
+func (l Layout) Path(dir string, date time.Time) string {
+	return filepath.Join(dir, l.Name(date))
+}
+
+// Match reports the date of the entry at name, a path relative to the
+// journal folder, if it is where the layout puts that date's entry.
+func (l Layout) Match(name string, loc *time.Location) (time.Time, bool) {
+	l = l.orDefault()
+	rel, ok := strings.CutSuffix(filepath.ToSlash(name), ".md")
+	if !ok {
+		return time.Time{}, false
+	}
+	m := l.re.FindStringSubmatch(rel)
+	if m == nil {
+		return time.Time{}, false
+	}
+	year, month, day, yday := -1, -1, -1, -1
+	set := func(v *int, n int) {
+		if *v < 0 {
+			*v = n
+		}
+	}
+	i := 1
+	for _, p := range l.parts {
+		if p.tok == nil {
+			continue
+		}
+		s := m[i]
+		i++
+		n, _ := strconv.Atoi(s)
+		switch p.tok.name {
+		case "YYYY":
+			set(&year, n)
+		case "YY":
+			set(&year, 2000+n)
+		case "MM", "M":
+			set(&month, n)
+		case "MMM", "MMMM":
+			for mo := time.January; mo <= time.December; mo++ {
+				if s == mo.String() || s == mo.String()[:3] {
+					set(&month, int(mo))
+				}
+			}
+		case "DD", "D":
+			set(&day, n)
+		case "DDDD":
+			set(&yday, n)
+		}
+	}
+	var date time.Time
+	if month > 0 && day > 0 {
+		date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	} else {
+		date = time.Date(year, time.January, yday, 0, 0, 0, 0, loc)
+	}
+	// Rejects impossible dates such as 2025-02-30 and fields that
+	// disagree, such as a weekday that is not the date's.
+	if l.format(date) != rel {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// Entry is a daily entry found in the journal folder.
+type Entry struct {
+	Date time.Time
+	Path string
+}
+
+// Entries lists the daily entries in the journal folder dir, oldest first.
+// Files and folders whose names start with a dot, such as .obsidian, are
+// skipped, as are files the layout does not name. A missing folder has no
+// entries.
+func (l Layout) Entries(fsys fs.FS, dir string) ([]Entry, error) {
+	var entries []Entry
+	err := Walk(fsys, dir, func(rel string) error {
+		if date, ok := l.Match(rel, time.Local); ok {
+			entries = append(entries, Entry{Date: date, Path: filepath.Join(dir, rel)})
+		}
+		return nil
+	})
+	if errors.Is(err, iofs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return a.Date.Compare(b.Date) })
+	return entries, nil
+}
+
+// Walk calls fn with the path, relative to dir, of every Markdown file
+// below dir, skipping hidden files and folders.
+func Walk(fsys fs.FS, dir string, fn func(rel string) error) error {
+	return walk(fsys, dir, "", fn)
+}
+
+func walk(fsys fs.FS, root, rel string, fn func(string) error) error {
+	files, err := fsys.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		name := path.Join(rel, f.Name())
+		if f.IsDir() {
+			err = walk(fsys, root, name, fn)
+		} else if strings.HasSuffix(f.Name(), ".md") {
+			err = fn(filepath.FromSlash(name))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package layout
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"journal-cli/internal/fs"
+)
+
+func TestLayoutName(t *testing.T) {
+	date := time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", "2025-12-29.md"},
+		{"YYYY-MM-DD", "2025-12-29.md"},
+		{"YYYY/MM/YYYY-MM-DD", "2025/12/2025-12-29.md"},
+		{"YYYY/YYYY-MM-DD dddd.md", "2025/2025-12-29 Monday.md"},
+		{"YYYY/MMMM/D MMM YY, ddd", "2025/December/29 Dec 25, Mon.md"},
+		{"GGGG/[W]WW/YYYY-MM-DD", "2026/W01/2025-12-29.md"},
+		{"[Daily]/YYYY/[Q]Q/DDDD", "Daily/2025/Q4/363.md"},
+	}
+	for _, tt := range tests {
+		l := Layout{}
+		if tt.pattern != "" {
+			var err error
+			if l, err = Parse(tt.pattern); err != nil {
+				t.Errorf("Parse(%q): %v", tt.pattern, err)
+				continue
+			}
+		}
+		name := l.Name(date)
+		if name != filepath.FromSlash(tt.want) {
+			t.Errorf("%q: Name = %q, want %q", tt.pattern, name, tt.want)
+		}
+		if got, ok := l.Match(name, time.UTC); !ok || !got.Equal(date) {
+			t.Errorf("%q: Match(%q) = %v, %v", tt.pattern, name, got, ok)
+		}
+	}
+}
+
+func TestLayoutMatchRejects(t *testing.T) {
+	l, err := Parse("YYYY/MM/YYYY-MM-DD ddd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"2025/12/2025-12-29 Tue.md", // Wrong weekday
+		"2025/11/2025-12-29 Mon.md", // Folder disagrees
+		"2025/02/2025-02-30 Sun.md", // No such day
+		"2025/12/2025-12-29 Mon.txt",
+		"2025-12-29.md",
+		"2025-W52.md",
+	} {
+		if d, ok := l.Match(filepath.FromSlash(name), time.UTC); ok {
+			t.Errorf("Match(%q) = %v, want no match", name, d)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"YYYY-MM":           "does not name the day",
+		"MM-DD":             "does not name the day",
+		"[Daily/YYYY-MM-DD": "unclosed",
+		"/YYYY-MM-DD":       "relative path",
+		"../YYYY-MM-DD":     "relative path",
+		"YYYY//MM-DD":       "relative path",
+	} {
+		_, err := Parse(pattern)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) = %v, want error containing %q", pattern, err, want)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	mem := fs.NewMemFS()
+	for _, name := range []string{
+		"2025/12/2025-12-30.md",
+		"2025/11/2025-11-02.md",
+		"2025/12/notes.md",
+		"2025-W52.md",
+		".trash/2025/12/2025-12-01.md",
+	} {
+		if err := mem.WriteFile(filepath.Join("j", filepath.FromSlash(name)), []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := Parse("YYYY/MM/YYYY-MM-DD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := l.Entries(mem, "j")
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Date.Format("2006-01-02")+" "+filepath.ToSlash(e.Path))
+	}
+	want := []string{"2025-11-02 j/2025/11/2025-11-02.md", "2025-12-30 j/2025/12/2025-12-30.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Entries = %q, want %q", got, want)
+	}
+
+	if entries, err := l.Entries(mem, "missing"); err != nil || len(entries) != 0 {
+		t.Errorf("Entries(missing) = %v, %v", entries, err)
+	}
+}
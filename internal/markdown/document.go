@@ -0,0 +1,146 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// document is the line-level structure of an entry file. It keeps every
+// line verbatim so that sections the journal does not model survive a
+// parse/generate round trip untouched.
+type document struct {
+	open, close  string   // frontmatter fences as written ("---")
+	frontmatter  []string // lines between the fences
+	preamble     []string // lines between the frontmatter and the first "## " heading
+	sections     []*section
+	finalNewline bool
+}
+
+// section is a "## " heading and the lines up to the next one.
+type section struct {
+	raw     string   // heading line as written
+	heading string   // heading text without the "## " marker
+	lines   []string // body lines, verbatim, including blank lines
+
+	// duplicate is set on a section repeating the heading of an earlier
+	// managed section, or the question it answers. The journal leaves it
+	// alone like any section it does not manage.
+	duplicate bool
+}
+
+func parseDocument(content []byte) (*document, error) {
+	text := string(content)
+	doc := &document{finalNewline: strings.HasSuffix(text, "\n")}
+	text = strings.TrimSuffix(text, "\n")
+	lines := strings.Split(text, "\n")
+
+	if len(lines) == 0 || trimCR(lines[0]) != "---" {
+		return nil, fmt.Errorf("invalid markdown format: missing frontmatter: %w", ErrNotEntry)
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if trimCR(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, fmt.Errorf("invalid markdown format: unterminated frontmatter")
+	}
+	doc.open, doc.close = lines[0], lines[end]
+	doc.frontmatter = lines[1:end]
+
+	var cur *section
+	inFence := false
+	for _, line := range lines[end+1:] {
+		t := trimCR(line)
+		if isFence(t) {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(t, "## ") {
+			cur = &section{raw: line, heading: strings.TrimSpace(t[3:])}
+			doc.sections = append(doc.sections, cur)
+			continue
+		}
+		if cur == nil {
+			doc.preamble = append(doc.preamble, line)
+		} else {
+			cur.lines = append(cur.lines, line)
+		}
+	}
+	markDuplicates(doc.sections)
+
+	return doc, nil
+}
+
+// markDuplicates marks the managed sections after the first of their kind,
+// or for questions after the first answering the same question.
+func markDuplicates(sections []*section) {
+	seen := map[sectionKind]bool{}
+	seenQuestions := map[string]bool{}
+	for _, s := range sections {
+		switch k, _ := s.kind(); k {
+		case sectionUnknown:
+		case sectionQuestion:
+			key := answerKey(s)
+			s.duplicate = seenQuestions[key]
+			seenQuestions[key] = true
+		default:
+			s.duplicate = seen[k]
+			seen[k] = true
+		}
+	}
+}
+
+func (d *document) bytes() []byte {
+	var all []string
+	all = append(all, d.open)
+	all = append(all, d.frontmatter...)
+	all = append(all, d.close)
+	all = append(all, d.preamble...)
+	for _, s := range d.sections {
+		all = append(all, s.raw)
+		all = append(all, s.lines...)
+	}
+	out := strings.Join(all, "\n")
+	if d.finalNewline {
+		out += "\n"
+	}
+	return []byte(out)
+}
+
+// newSection builds a section with the given heading text and body.
+func newSection(heading string, body []string) *section {
+	return &section{raw: "## " + heading, heading: heading, lines: body}
+}
+
+// isFence reports whether line opens or closes a fenced code block.
+func isFence(line string) bool {
+	t := strings.TrimLeft(line, " ")
+	if len(line)-len(t) > 3 {
+		return false
+	}
+	return strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~")
+}
+
+func trimCR(s string) string {
+	return strings.TrimSuffix(s, "\r")
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// splitBlankEdges splits lines into leading blank lines, content and
+// trailing blank lines.
+func splitBlankEdges(lines []string) (lead, body, trail []string) {
+	start := 0
+	for start < len(lines) && isBlank(lines[start]) {
+		start++
+	}
+	end := len(lines)
+	for end > start && isBlank(lines[end-1]) {
+		end--
+	}
+	return lines[:start], lines[start:end], lines[end:]
+}
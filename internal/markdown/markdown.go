@@ -1,9 +1,12 @@
 package markdown
 
 import (
-	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,145 +15,674 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// Headings written for the sections the journal manages.
+const (
+	headingHighlight = "⭐️ Daily Highlight"
+	headingTodos     = "✅ Todos – Today"
+	headingBacklog   = "🔁 Backlog"
+	headingSummary   = "📊 Period Summary"
+	questionPrefix   = "🧠 "
+)
+
+// A question section records the ID of the template question it answers
+// on the line after its heading, so answers survive retitled questions:
+//
+//	## 🧠 How am I feeling today?
+//	<!-- question: mood -->
+const (
+	idMarkerOpen  = "<!-- question:"
+	idMarkerClose = "-->"
+)
+
+// ErrNotEntry is returned by ParseMarkdown for a file without the
+// frontmatter date of an entry, such as another note kept in the journal
+// folder.
+var ErrNotEntry = errors.New("not a journal entry")
+
 type FrontMatter struct {
 	Date      string `yaml:"date"`
+	Period    string `yaml:"period"` // Empty for daily entries
 	Template  string `yaml:"template"`
 	Mood      string `yaml:"mood"`
 	Energy    string `yaml:"energy"`
 	Highlight string `yaml:"highlight"`
+
+	Metrics map[string]float64 `yaml:"metrics,omitempty"`
 }
 
-func GenerateMarkdown(entry *domain.JournalEntry) ([]byte, error) {
-	// Frontmatter
-	fm := FrontMatter{
+// pairs returns the frontmatter keys in the order they are written. Review
+// entries have no mood, energy or highlight of their own.
+func (fm FrontMatter) pairs() [][2]string {
+	if fm.Period != "" {
+		return [][2]string{
+			{"date", fm.Date},
+			{"period", fm.Period},
+			{"template", fm.Template},
+		}
+	}
+	return [][2]string{
+		{"date", fm.Date},
+		{"template", fm.Template},
+		{"mood", fm.Mood},
+		{"energy", fm.Energy},
+		{"highlight", fm.Highlight},
+	}
+}
+
+func (fm FrontMatter) equal(other FrontMatter) bool {
+	return slices.Equal(fm.pairs(), other.pairs()) && maps.Equal(fm.Metrics, other.Metrics)
+}
+
+func frontMatterOf(entry *domain.JournalEntry) FrontMatter {
+	return FrontMatter{
 		Date:      entry.Date.Format("2006-01-02"),
+		Period:    string(entry.Period),
 		Template:  entry.Template,
 		Mood:      entry.Mood,
 		Energy:    entry.Energy,
 		Highlight: entry.Highlight,
+		Metrics:   entry.Metrics,
 	}
-	fmBytes, err := yaml.Marshal(fm)
+}
+
+type sectionKind int
+
+const (
+	sectionUnknown sectionKind = iota
+	sectionHighlight
+	sectionTodos
+	sectionBacklog
+	sectionSummary
+	sectionQuestion
+)
+
+// kind classifies a section by its heading. Only the exact headings the
+// journal writes are managed, so a section of the user's that merely
+// mentions "Todos" is kept as written. For question sections it also
+// returns the question the section answers.
+func (s *section) kind() (sectionKind, string) {
+	switch {
+	case s.duplicate:
+		return sectionUnknown, ""
+	case s.heading == headingHighlight:
+		return sectionHighlight, ""
+	case s.heading == headingTodos:
+		return sectionTodos, ""
+	case isBacklogHeading(s.heading):
+		return sectionBacklog, ""
+	case s.heading == headingSummary:
+		return sectionSummary, ""
+	case strings.HasPrefix(s.heading, questionPrefix):
+		return sectionQuestion, strings.TrimPrefix(s.heading, questionPrefix)
+	}
+	return sectionUnknown, ""
+}
+
+// GenerateMarkdown renders entry as Markdown. When the entry was parsed
+// from a file (entry.Raw is set) the original file is patched instead of
+// rewritten: unknown frontmatter keys, the preamble, sections the journal
+// does not manage and every unchanged section are kept byte for byte.
+func GenerateMarkdown(entry *domain.JournalEntry) ([]byte, error) {
+	var (
+		doc  *document
+		prev *domain.JournalEntry
+	)
+	if entry.Raw != nil {
+		if d, err := parseDocument(entry.Raw); err == nil {
+			if p, err := entryFromDocument(d); err == nil {
+				doc, prev = d, p
+			}
+		}
+	}
+	if doc == nil {
+		doc, prev = skeleton(entry)
+	}
+
+	if fm := frontMatterOf(entry); !fm.equal(frontMatterOf(prev)) {
+		lines, err := patchFrontMatter(doc.frontmatter, fm)
+		if err != nil {
+			return nil, err
+		}
+		doc.frontmatter = lines
+	}
+
+	syncSections(doc, entry, prev)
+
+	return doc.bytes(), nil
+}
+
+// skeleton returns an empty document for a new entry along with the
+// (empty) entry it represents. Reviews start without a Todos section.
+func skeleton(entry *domain.JournalEntry) (*document, *domain.JournalEntry) {
+	doc := &document{
+		open:         "---",
+		close:        "---",
+		preamble:     []string{"", "# " + entry.Period.Title(entry.Date), ""},
+		finalNewline: true,
+	}
+	if entry.Period == domain.PeriodDay {
+		doc.sections = []*section{newSection(headingTodos, []string{""})}
+	}
+	return doc, domain.NewJournalEntry(time.Time{}, "")
+}
+
+// patchFrontMatter sets the journal's keys in the YAML frontmatter while
+// keeping any other keys (tags, aliases, custom properties) and their order.
+func patchFrontMatter(lines []string, fm FrontMatter) ([]string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &root); err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	m := root.Content[0]
+
+	for _, kv := range fm.pairs() {
+		setMappingValue(m, kv[0], kv[1])
+	}
+	setMetrics(m, fm.Metrics)
+
+	out, err := yaml.Marshal(&root)
 	if err != nil {
 		return nil, err
 	}
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"), nil
+}
+
+// setMetrics replaces the "metrics" map, removing it when there are none.
+// Keys are sorted so the output does not depend on map order.
+func setMetrics(m *yaml.Node, metrics map[string]float64) {
+	idx := -1
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == "metrics" {
+			idx = i
+			break
+		}
+	}
+	if len(metrics) == 0 {
+		if idx >= 0 {
+			m.Content = append(m.Content[:idx], m.Content[idx+2:]...)
+		}
+		return
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range slices.Sorted(maps.Keys(metrics)) {
+		v := strconv.FormatFloat(metrics[k], 'f', -1, 64)
+		tag := "!!float"
+		if !strings.Contains(v, ".") {
+			tag = "!!int"
+		}
+		value.Content = append(value.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v},
+		)
+	}
+	if idx >= 0 {
+		m.Content[idx+1] = value
+		return
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "metrics"}, value)
+}
+
+func setMappingValue(m *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Value == value {
+			return
+		}
+		style := v.Style &^ (yaml.LiteralStyle | yaml.FoldedStyle)
+		*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style}
+		return
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// backlogHeading is the heading of the Backlog section, which names the
+// entry it was carried over from when known: "🔁 Backlog (from 2025-12-26)".
+func backlogHeading(from time.Time) string {
+	if from.IsZero() {
+		return headingBacklog
+	}
+	return headingBacklog + " (from " + from.Format("2006-01-02") + ")"
+}
+
+// isBacklogHeading reports whether heading was written by backlogHeading.
+func isBacklogHeading(heading string) bool {
+	return heading == headingBacklog || !backlogFrom(heading).IsZero() &&
+		heading == backlogHeading(backlogFrom(heading))
+}
+
+// backlogFrom reads the date from a heading written by backlogHeading.
+func backlogFrom(heading string) time.Time {
+	_, rest, ok := strings.Cut(heading, "(from ")
+	if !ok {
+		return time.Time{}
+	}
+	date, _, _ := strings.Cut(rest, ")")
+	d, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}
+	}
+	return d
+}
+
+// syncSections rewrites the managed sections of doc whose content differs
+// between prev (what doc currently says) and entry, removes sections that
+// no longer have content and inserts sections that are new.
+func syncSections(doc *document, entry, prev *domain.JournalEntry) {
+	todosChanged := !todosEqual(entry.Todos, prev.Todos)
+	backlogChanged := !todosEqual(entry.Backlog, prev.Backlog)
+
+	var (
+		out                                                []*section
+		haveHighlight, haveTodos, haveBacklog, haveSummary bool
+		seenQuestions                                      = map[string]bool{}
+	)
+	for _, s := range doc.sections {
+		kind, title := s.kind()
+		switch kind {
+		case sectionHighlight:
+			if entry.Highlight != prev.Highlight {
+				if entry.Highlight == "" {
+					continue
+				}
+				s.lines = textBody(entry.Highlight, s.lines)
+			}
+			haveHighlight = true
+		case sectionTodos:
+			if todosChanged {
+				s.lines = todoBody(entry.Todos, s.lines)
+			}
+			haveTodos = true
+		case sectionBacklog:
+			if backlogChanged {
+				if len(entry.Backlog) == 0 {
+					continue
+				}
+				s.lines = todoBody(entry.Backlog, s.lines)
+			}
+			if backlogHeading(entry.BacklogFrom) != backlogHeading(prev.BacklogFrom) {
+				*s = *newSection(backlogHeading(entry.BacklogFrom), s.lines)
+			}
+			haveBacklog = true
+		case sectionSummary:
+			if entry.Summary != prev.Summary {
+				if entry.Summary == "" {
+					continue
+				}
+				s.lines = textBody(entry.Summary, s.lines)
+			}
+			haveSummary = true
+		case sectionQuestion:
+			i, old := answerIndex(entry.Answers, s), answerIndex(prev.Answers, s)
+			if i < 0 || old < 0 || entry.Answers[i].Text != prev.Answers[old].Text {
+				if i < 0 || seenQuestions[entry.Answers[i].ID] {
+					continue
+				}
+				s.lines = questionBody(entry.Answers[i], s.lines)
+			}
+			a := entry.Answers[i]
+			if id, _ := questionID(s.lines); id != a.ID && a.ID != a.Question {
+				s.lines = questionBody(a, s.lines)
+			}
+			if title != a.Question {
+				*s = *newSection(questionPrefix+a.Question, s.lines)
+			}
+			seenQuestions[a.ID] = true
+		}
+		out = append(out, s)
+	}
+
+	if !haveHighlight && entry.Highlight != "" {
+		out = insertSection(doc, out, newSection(headingHighlight, textBody(entry.Highlight, nil)),
+			firstIndex(out, sectionTodos, sectionBacklog, sectionQuestion))
+	}
+	if !haveTodos && len(entry.Todos) > 0 {
+		out = insertSection(doc, out, newSection(headingTodos, todoBody(entry.Todos, nil)),
+			firstIndex(out, sectionBacklog, sectionQuestion))
+	}
+	if !haveBacklog && len(entry.Backlog) > 0 {
+		out = insertSection(doc, out, newSection(backlogHeading(entry.BacklogFrom), todoBody(entry.Backlog, nil)),
+			firstIndex(out, sectionQuestion))
+	}
+	if !haveSummary && entry.Summary != "" {
+		out = insertSection(doc, out, newSection(headingSummary, textBody(entry.Summary, nil)),
+			firstIndex(out, sectionQuestion))
+	}
+	// New answers go right after the answer that precedes them in the
+	// entry, so sections follow template order.
+	for i, a := range entry.Answers {
+		if seenQuestions[a.ID] {
+			continue
+		}
+		pos := firstIndex(out, sectionQuestion)
+		for j := i - 1; j >= 0; j-- {
+			if k := questionIndex(out, entry.Answers, entry.Answers[j].ID); k >= 0 {
+				pos = k + 1
+				break
+			}
+		}
+		out = insertSection(doc, out, newSection(questionPrefix+a.Question, questionBody(a, nil)), pos)
+		seenQuestions[a.ID] = true
+	}
+	orderQuestions(out, entry.Answers)
 
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.Write(fmBytes)
-	sb.WriteString("---\n\n")
+	doc.sections = out
+}
 
-	sb.WriteString(fmt.Sprintf("# Daily Journal – %s\n\n", entry.Date.Format("2006-01-02")))
+// firstIndex returns the index of the first section of any of the given
+// kinds, or len(sections) when there is none.
+func firstIndex(sections []*section, kinds ...sectionKind) int {
+	for i, s := range sections {
+		k, _ := s.kind()
+		for _, want := range kinds {
+			if k == want {
+				return i
+			}
+		}
+	}
+	return len(sections)
+}
 
-	if entry.Highlight != "" {
-		sb.WriteString(fmt.Sprintf("## ⭐️ Daily Highlight\n%s\n\n", entry.Highlight))
+// orderQuestions reorders the question sections among the positions they
+// already occupy so that they follow answers. Other sections stay put, and
+// sections without an answer keep their relative order after the rest.
+// Trailing blank lines belong to the position, not the section, so the
+// spacing between sections is unchanged.
+func orderQuestions(sections []*section, answers []domain.Answer) {
+	var slots []int
+	var questions []*section
+	for i, s := range sections {
+		if k, _ := s.kind(); k == sectionQuestion {
+			slots = append(slots, i)
+			questions = append(questions, s)
+		}
+	}
+	rankOf := func(s *section) int {
+		if i := answerIndex(answers, s); i >= 0 {
+			return i
+		}
+		return len(answers)
+	}
+	sort.SliceStable(questions, func(i, j int) bool {
+		return rankOf(questions[i]) < rankOf(questions[j])
+	})
+	trails := make([][]string, len(slots))
+	for i, slot := range slots {
+		_, _, trails[i] = splitBlankEdges(sections[slot].lines)
 	}
+	for i, slot := range slots {
+		q := questions[i]
+		if q != sections[slot] {
+			lead, body, _ := splitBlankEdges(q.lines)
+			q.lines = append(append(append([]string{}, lead...), body...), trails[i]...)
+			sections[slot] = q
+		}
+	}
+}
 
-	sb.WriteString("## ✅ Todos – Today\n")
-	for _, todo := range entry.Todos {
-		check := " "
-		if todo.Done {
-			check = "x"
+// questionID splits the ID marker off the body of a question section. It
+// returns the ID ("" when there is no marker) and the remaining lines.
+func questionID(lines []string) (string, []string) {
+	lead, body, _ := splitBlankEdges(lines)
+	if len(body) == 0 {
+		return "", lines
+	}
+	t := strings.TrimSpace(body[0])
+	if !strings.HasPrefix(t, idMarkerOpen) || !strings.HasSuffix(t, idMarkerClose) {
+		return "", lines
+	}
+	id := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(t, idMarkerOpen), idMarkerClose))
+	return id, lines[len(lead)+1:]
+}
+
+// idMarker returns the marker line for id.
+func idMarker(id string) string {
+	return idMarkerOpen + " " + id + " " + idMarkerClose
+}
+
+// questionBody builds the body of a question section answering a, keeping
+// the blank lines of old around the text.
+func questionBody(a domain.Answer, old []string) []string {
+	_, rest := questionID(old)
+	body := textBody(a.Text, rest)
+	if a.ID == "" || a.ID == a.Question {
+		// Templates without IDs key answers by title, which the heading
+		// already records.
+		return body
+	}
+	return append([]string{idMarker(a.ID)}, body...)
+}
+
+// answerKey returns the key of the answer held by question section s: its
+// ID marker, or the question title for entries written without IDs.
+func answerKey(s *section) string {
+	_, title := s.kind()
+	if id, _ := questionID(s.lines); id != "" {
+		return id
+	}
+	return title
+}
+
+// answerIndex returns the index of the answer held by question section s.
+// Sections without an ID marker also match an answer by its title, which
+// is how IDs get added to entries written before they existed.
+func answerIndex(answers []domain.Answer, s *section) int {
+	key := answerKey(s)
+	for i, a := range answers {
+		if a.ID == key {
+			return i
+		}
+	}
+	if id, _ := questionID(s.lines); id == "" {
+		_, title := s.kind()
+		for i, a := range answers {
+			if a.Question == title {
+				return i
+			}
 		}
-		sb.WriteString(fmt.Sprintf("- [%s] %s\n", check, todo.Text))
 	}
-	sb.WriteString("\n")
+	return -1
+}
 
-	if len(entry.Backlog) > 0 {
-		// Backlog usually comes from previous day, so we might want to label it differently
-		// But for the current day's file, it's just backlog items that were carried over
-		// The prompt says "Backlog (from YYYY-MM-DD)" but that date changes.
-		// For simplicity, we'll just list them.
-		// Or maybe we should track where they came from?
-		// The prompt says: "## 🔁 Backlog (from YYYY-MM-DD)"
-		// We'll assume for now we just list them under a generic Backlog or we need to know the source date.
-		// Let's just use "Backlog" for now or "Backlog (Previous)"
-		sb.WriteString("## 🔁 Backlog\n")
-		for _, todo := range entry.Backlog {
-			check := " "
-			if todo.Done {
-				check = "x"
+// questionIndex returns the index of the section holding the answer with
+// key id, or -1.
+func questionIndex(sections []*section, answers []domain.Answer, id string) int {
+	for i, s := range sections {
+		if k, _ := s.kind(); k == sectionQuestion {
+			if j := answerIndex(answers, s); j >= 0 && answers[j].ID == id {
+				return i
 			}
-			sb.WriteString(fmt.Sprintf("- [%s] %s\n", check, todo.Text))
 		}
-		sb.WriteString("\n")
 	}
+	return -1
+}
 
-	for q, a := range entry.Questions {
-		sb.WriteString(fmt.Sprintf("## 🧠 %s\n", q))
-		sb.WriteString(fmt.Sprintf("%s\n\n", a))
+// insertSection inserts s at index i, making sure the content before it
+// ends with a blank line.
+func insertSection(doc *document, sections []*section, s *section, i int) []*section {
+	before := &doc.preamble
+	if i > 0 {
+		before = &sections[i-1].lines
 	}
+	if n := len(*before); n == 0 || !isBlank((*before)[n-1]) {
+		*before = append(*before, "")
+	}
+	sections = append(sections, nil)
+	copy(sections[i+1:], sections[i:])
+	sections[i] = s
+	return sections
+}
 
-	return []byte(sb.String()), nil
+// textBody replaces the content of a free-text section, keeping the blank
+// lines that surrounded the old content. New sections get one trailing
+// blank line.
+func textBody(text string, old []string) []string {
+	lead, _, trail := splitBlankEdges(old)
+	if old == nil {
+		trail = []string{""}
+	}
+	var body []string
+	body = append(body, lead...)
+	if text != "" {
+		body = append(body, strings.Split(text, "\n")...)
+	}
+	return append(body, trail...)
+}
+
+// todoItem is a task line together with the lines that belong to it
+// (nested list items, notes) in the original section.
+type todoItem struct {
+	todo  domain.Todo
+	raw   string
+	extra []string
+}
+
+// parseTodoLines splits a todo section body into the lines before the
+// first task, the tasks with their attached lines, and trailing blank
+// lines. Only unindented "- [" lines are tasks; indented ones are nested
+// items of the task above.
+func parseTodoLines(lines []string) (head []string, items []todoItem, trail []string) {
+	body := lines
+	end := len(body)
+	for end > 0 && isBlank(body[end-1]) {
+		end--
+	}
+	body, trail = body[:end], body[end:]
+
+	for _, line := range body {
+		if todo, ok := parseTodo(line); ok {
+			items = append(items, todoItem{todo: todo, raw: line})
+			continue
+		}
+		if len(items) == 0 {
+			head = append(head, line)
+		} else {
+			items[len(items)-1].extra = append(items[len(items)-1].extra, line)
+		}
+	}
+	return head, items, trail
+}
+
+// todoBody renders todos into a section body. Tasks that existed in the
+// old body keep their original line and any nested lines below them.
+func todoBody(todos []domain.Todo, old []string) []string {
+	head, items, trail := parseTodoLines(old)
+	if old == nil {
+		trail = []string{""}
+	}
+	used := make([]bool, len(items))
+
+	var body []string
+	body = append(body, head...)
+	for _, todo := range todos {
+		line, extra := FormatTodo(todo), []string(nil)
+		for i, it := range items {
+			if used[i] || !it.todo.Same(todo) {
+				continue
+			}
+			used[i] = true
+			if it.todo.Equal(todo) {
+				line = it.raw
+			}
+			extra = it.extra
+			break
+		}
+		body = append(body, line)
+		body = append(body, extra...)
+	}
+	return append(body, trail...)
+}
+
+func todosEqual(a, b []domain.Todo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func ParseMarkdown(content []byte) (*domain.JournalEntry, error) {
-	// Split frontmatter and body
-	parts := bytes.SplitN(content, []byte("---"), 3)
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("invalid markdown format: missing frontmatter")
+	doc, err := parseDocument(content)
+	if err != nil {
+		return nil, err
 	}
 
+	entry, err := entryFromDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	entry.Raw = append([]byte(nil), content...)
+	return entry, nil
+}
+
+func entryFromDocument(doc *document) (*domain.JournalEntry, error) {
 	// Parse Frontmatter
 	var fm FrontMatter
-	if err := yaml.Unmarshal(parts[1], &fm); err != nil {
+	if err := yaml.Unmarshal([]byte(strings.Join(doc.frontmatter, "\n")), &fm); err != nil {
 		return nil, err
 	}
 
+	if fm.Date == "" {
+		return nil, fmt.Errorf("invalid markdown format: no date in frontmatter: %w", ErrNotEntry)
+	}
 	date, err := time.Parse("2006-01-02", fm.Date)
 	if err != nil {
 		return nil, err
 	}
 
+	period, err := domain.ParsePeriod(fm.Period)
+	if err != nil {
+		return nil, err
+	}
+
 	entry := domain.NewJournalEntry(date, fm.Template)
+	entry.Period = period
 	entry.Mood = fm.Mood
 	entry.Energy = fm.Energy
 	entry.Highlight = fm.Highlight
+	maps.Copy(entry.Metrics, fm.Metrics)
 
 	// Parse Body
-	scanner := bufio.NewScanner(bytes.NewReader(parts[2]))
-	var currentSection string
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		if strings.HasPrefix(line, "## ") {
-			currentSection = strings.TrimPrefix(line, "## ")
-			continue
-		}
-
-		if strings.HasPrefix(line, "- [") {
-			// Todo item
-			done := strings.HasPrefix(line, "- [x]") || strings.HasPrefix(line, "- [X]")
-			text := strings.TrimSpace(line[5:])
-			todo := domain.Todo{Text: text, Done: done}
-
-			if strings.Contains(currentSection, "Todos") {
-				entry.Todos = append(entry.Todos, todo)
-			} else if strings.Contains(currentSection, "Backlog") {
-				entry.Backlog = append(entry.Backlog, todo)
+	for _, s := range doc.sections {
+		kind, title := s.kind()
+		switch kind {
+		case sectionTodos, sectionBacklog:
+			if kind == sectionBacklog {
+				entry.BacklogFrom = backlogFrom(s.heading)
 			}
-		} else {
-			// Probably an answer to a question
-			// This is a simple parser, assuming questions are headers and answers follow
-			// We need to match questions from the template or just store them as found
-			// For now, let's assume any other section is a question
-			if currentSection != "" && !strings.Contains(currentSection, "Todos") && !strings.Contains(currentSection, "Backlog") {
-				// Clean up the section name (remove emoji if present)
-				// This is a bit hacky, but works for the MVP
-				q := currentSection
-				if idx := strings.Index(q, " "); idx != -1 {
-					// q = q[idx+1:] // Remove emoji? Maybe not, keep it for now
-				}
-				// Append to existing answer if multi-line?
-				if val, ok := entry.Questions[q]; ok {
-					entry.Questions[q] = val + "\n" + line
+			_, items, _ := parseTodoLines(s.lines)
+			for _, it := range items {
+				if kind == sectionTodos {
+					entry.Todos = append(entry.Todos, it.todo)
 				} else {
-					entry.Questions[q] = line
+					entry.Backlog = append(entry.Backlog, it.todo)
 				}
 			}
+		case sectionSummary:
+			_, body, _ := splitBlankEdges(s.lines)
+			entry.Summary = strings.Join(body, "\n")
+		case sectionQuestion:
+			// Entries written before question IDs existed only record
+			// the title; callers that know the template map it to an ID.
+			id, rest := questionID(s.lines)
+			if id == "" {
+				id = title
+			}
+			_, body, _ := splitBlankEdges(rest)
+			entry.SetAnswer(id, title, strings.Join(body, "\n"))
 		}
 	}
 
@@ -1,6 +1,8 @@
 package markdown
 
 import (
+    "os"
+    "path/filepath"
     "strings"
     "testing"
     "time"
@@ -14,9 +16,9 @@ func TestGenerateAndParseRoundtrip(t *testing.T) {
     entry.Mood = "Calm"
     entry.Energy = "Medium"
     entry.Highlight = "Wrote tests"
-    entry.Todos = append(entry.Todos, domain.Todo{Text: "Do thing", Done: false})
-    entry.Backlog = append(entry.Backlog, domain.Todo{Text: "Carryover", Done: false})
-    entry.Questions["What did I learn?"] = "Testing roundtrip"
+    entry.Todos = append(entry.Todos, domain.Todo{Text: "Do thing", Status: domain.StatusOpen})
+    entry.Backlog = append(entry.Backlog, domain.Todo{Text: "Carryover", Status: domain.StatusOpen})
+    entry.SetAnswer("What did I learn?", "What did I learn?", "Testing roundtrip")
 
     md, err := GenerateMarkdown(entry)
     if err != nil {
@@ -46,14 +48,14 @@ func TestGenerateAndParseRoundtrip(t *testing.T) {
 
     // Keys may include emoji prefixes; find answer by substring match
     found := false
-    for k, v := range parsed.Questions {
-        if strings.Contains(k, "What did I learn") && v == "Testing roundtrip" {
+    for _, a := range parsed.Answers {
+        if strings.Contains(a.Question, "What did I learn") && a.Text == "Testing roundtrip" {
             found = true
             break
         }
     }
     if !found {
-        t.Fatalf("questions mismatch: %v", parsed.Questions)
+        t.Fatalf("answers mismatch: %v", parsed.Answers)
     }
 }
 
@@ -63,3 +65,378 @@ func TestParseMalformed(t *testing.T) {
         t.Fatalf("expected error parsing malformed markdown")
     }
 }
+
+// TestGoldenRoundTrip checks that re-saving an untouched entry reproduces
+// the file byte for byte, whatever it contains.
+func TestGoldenRoundTrip(t *testing.T) {
+    files, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+    if err != nil || len(files) == 0 {
+        t.Fatalf("no golden files found: %v", err)
+    }
+
+    for _, file := range files {
+        t.Run(filepath.Base(file), func(t *testing.T) {
+            want, err := os.ReadFile(file)
+            if err != nil {
+                t.Fatalf("read golden file: %v", err)
+            }
+
+            entry, err := ParseMarkdown(want)
+            if err != nil {
+                t.Fatalf("ParseMarkdown error: %v", err)
+            }
+
+            got, err := GenerateMarkdown(entry)
+            if err != nil {
+                t.Fatalf("GenerateMarkdown error: %v", err)
+            }
+
+            if string(got) != string(want) {
+                t.Fatalf("round trip changed the file\n--- got ---\n%s\n--- want ---\n%s", got, want)
+            }
+        })
+    }
+}
+
+func TestEditPreservesUnknownContent(t *testing.T) {
+    original, err := os.ReadFile(filepath.Join("testdata", "obsidian-edited.md"))
+    if err != nil {
+        t.Fatalf("read golden file: %v", err)
+    }
+
+    entry, err := ParseMarkdown(original)
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+
+    if len(entry.Todos) != 2 {
+        t.Fatalf("nested items should not be parsed as todos: %v", entry.Todos)
+    }
+
+    entry.Mood = "Better"
+    entry.Todos[1].Status = domain.StatusDone
+    entry.Todos = append(entry.Todos, domain.Todo{Text: "Share postmortem"})
+    entry.SetAnswer("🧠 How am I feeling today (emotionally)?", "🧠 How am I feeling today (emotionally)?", "Rested now.")
+    entry.SetAnswer("gratitude", "🙏 One thing I’m grateful for today", "Coffee")
+
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    got := string(out)
+
+    for _, keep := range []string{
+        "tags:\n    - journal\n    - work/infra\n",
+        "aliases: [Monday]\n",
+        "rating: 4\n",
+        "A paragraph written above the first section in Obsidian.\n![[sunrise.png]]\n\n\n## ⭐️",
+        "- [X] Fix CI\n    - [x] bisect the failing job\n    - notes: it was the cache\n",
+        "- [x] Write postmortem\n  continuation line for the postmortem\n- [ ] Share postmortem\n",
+        "## Notes\nFree-form notes added in Obsidian.\n![diagram](diagram.png)\n",
+        "## 🧠 💻 What did I work on or think about today?\nThe build cache.\n",
+        "## 🧠 🧠 How am I feeling today (emotionally)?\n\nRested now.\n\n## Notes",
+        "## 🧠 🙏 One thing I’m grateful for today\n<!-- question: gratitude -->\nCoffee\n",
+    } {
+        if !strings.Contains(got, keep) {
+            t.Errorf("expected output to contain %q\n--- got ---\n%s", keep, got)
+        }
+    }
+
+    if strings.Contains(got, "## not a heading inside code\nfmt") {
+        t.Errorf("code block of a rewritten answer should be replaced")
+    }
+
+    reparsed, err := ParseMarkdown(out)
+    if err != nil {
+        t.Fatalf("ParseMarkdown of edited output error: %v", err)
+    }
+    if reparsed.Mood != "Better" || len(reparsed.Todos) != 3 || !reparsed.Todos[1].Done() {
+        t.Errorf("edits were not persisted: mood=%q todos=%v", reparsed.Mood, reparsed.Todos)
+    }
+}
+
+// TestUserSectionsNamedLikeManaged checks that only the journal's own
+// headings are managed: sections that mention "Todos" or "Backlog", and a
+// second copy of a managed section, are neither parsed nor rewritten.
+func TestUserSectionsNamedLikeManaged(t *testing.T) {
+    md := "---\ndate: 2025-12-29\n---\n" +
+        "## ✅ Todos – Today\n- [ ] Real todo\n\n" +
+        "## Notes about Todos\nmy precious notes\n- [ ] not a journal todo\n\n" +
+        "## Backlog grooming ideas\n- [ ] not carried over\n\n" +
+        "## ✅ Todos – Today\n- [ ] second copy\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if len(entry.Todos) != 1 || entry.Todos[0].Text != "Real todo" || len(entry.Backlog) != 0 {
+        t.Fatalf("user sections parsed as managed: todos=%v backlog=%v", entry.Todos, entry.Backlog)
+    }
+
+    entry.Todos = append(entry.Todos, domain.Todo{Text: "New todo"})
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    want := "---\ndate: 2025-12-29\n---\n" +
+        "## ✅ Todos – Today\n- [ ] Real todo\n- [ ] New todo\n\n" +
+        "## Notes about Todos\nmy precious notes\n- [ ] not a journal todo\n\n" +
+        "## Backlog grooming ideas\n- [ ] not carried over\n\n" +
+        "## ✅ Todos – Today\n- [ ] second copy\n"
+    if string(out) != want {
+        t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+    }
+
+    reparsed, err := ParseMarkdown(out)
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if len(reparsed.Todos) != 2 || reparsed.Todos[1].Text != "New todo" {
+        t.Fatalf("todos not persisted: %v", reparsed.Todos)
+    }
+}
+
+func TestParseIgnoresHeadingsInCodeBlocks(t *testing.T) {
+    md := "---\ndate: 2025-12-30\n---\n## 🧠 Snippet\n```\n## 🧠 Not a question\n```\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if answer, _ := entry.Answer("Snippet"); len(entry.Answers) != 1 || answer != "```\n## 🧠 Not a question\n```" {
+        t.Fatalf("unexpected answers: %v", entry.Answers)
+    }
+}
+
+func TestGenerateOrdersQuestionsByAnswers(t *testing.T) {
+    date := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
+    entry := domain.NewJournalEntry(date, "daily")
+    entry.SetAnswer("a", "First", "1")
+    entry.SetAnswer("c", "Third", "3")
+
+    for i := 0; i < 5; i++ {
+        entry.Answers = append(entry.Answers[:0:0], entry.Answers...)
+        md, err := GenerateMarkdown(entry)
+        if err != nil {
+            t.Fatalf("GenerateMarkdown error: %v", err)
+        }
+        if !strings.Contains(string(md), "## 🧠 First\n<!-- question: a -->\n1\n\n## 🧠 Third\n<!-- question: c -->\n3\n") {
+            t.Fatalf("questions out of order:\n%s", md)
+        }
+    }
+
+    // An edited file gets the new answer in place and an out-of-order
+    // file is put back in answer order, around other sections.
+    md := "---\ndate: 2025-12-30\n---\n## 🧠 Third\n3\n\n## Notes\nkeep\n\n## 🧠 First\n1\n"
+    parsed, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    parsed.SetAnswer("Second", "Second", "2")
+    parsed.SortAnswers([]string{"First", "Second", "Third"})
+
+    out, err := GenerateMarkdown(parsed)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    want := "---\ndate: 2025-12-30\n---\n## 🧠 First\n1\n\n## Notes\nkeep\n\n## 🧠 Second\n2\n\n## 🧠 Third\n3\n\n"
+    if string(out) != want {
+        t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+    }
+}
+
+func TestQuestionIDsSurviveRetitling(t *testing.T) {
+    md := "---\ndate: 2025-12-30\n---\n## 🧠 How do I feel?\n<!-- question: mood -->\nFine\n\n## 🧠 Legacy title\nOld answer\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if answer, _ := entry.Answer("mood"); answer != "Fine" {
+        t.Fatalf("answer not keyed by ID: %v", entry.Answers)
+    }
+    if answer, _ := entry.Answer("Legacy title"); answer != "Old answer" {
+        t.Fatalf("answer without ID not keyed by title: %v", entry.Answers)
+    }
+
+    // The template retitled "mood" and gave the legacy question an ID.
+    entry.SetAnswer("mood", "How am I feeling?", "Fine")
+    entry.Answers[1].ID = "legacy"
+
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    want := "---\ndate: 2025-12-30\n---\n## 🧠 How am I feeling?\n<!-- question: mood -->\nFine\n\n## 🧠 Legacy title\n<!-- question: legacy -->\nOld answer\n"
+    if string(out) != want {
+        t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+    }
+}
+
+func TestMetricsFrontMatter(t *testing.T) {
+    md := "---\ndate: 2025-12-30\ntags: [journal]\n---\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    entry.Metrics["sleep"] = 7.5
+    entry.Metrics["focus"] = 4
+
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    if !strings.Contains(string(out), "highlight: \"\"\nmetrics:\n    focus: 4\n    sleep: 7.5\n---\n") {
+        t.Fatalf("unexpected frontmatter:\n%s", out)
+    }
+
+    reparsed, err := ParseMarkdown(out)
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if reparsed.Metrics["sleep"] != 7.5 || reparsed.Metrics["focus"] != 4 {
+        t.Fatalf("metrics not parsed: %v", reparsed.Metrics)
+    }
+
+    reparsed.Metrics = nil
+    out, err = GenerateMarkdown(reparsed)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    if strings.Contains(string(out), "metrics") {
+        t.Fatalf("metrics should be removed:\n%s", out)
+    }
+}
+
+func TestBacklogFromHeading(t *testing.T) {
+    md := "---\ndate: 2025-12-29\n---\n## 🔁 Backlog (from 2025-12-26)\n- [ ] Carried\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if got := entry.BacklogFrom.Format("2006-01-02"); got != "2025-12-26" {
+        t.Fatalf("BacklogFrom = %s, want 2025-12-26", got)
+    }
+
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    if string(out) != md {
+        t.Fatalf("unchanged entry was rewritten:\n%s", out)
+    }
+
+    entry.BacklogFrom = time.Date(2025, 12, 27, 0, 0, 0, 0, time.Local)
+    out, err = GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    want := "---\ndate: 2025-12-29\n---\n## 🔁 Backlog (from 2025-12-27)\n- [ ] Carried\n"
+    if string(out) != want {
+        t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+    }
+}
+
+func TestTodoMetadata(t *testing.T) {
+    line := "- [ ] Renew passport [carried:: 3] [carried_on:: 2025-12-29] 🆔 x3kq9a ➕ 2025-12-20"
+    md := "---\ndate: 2025-12-29\n---\n## 🔁 Backlog\n" + line + "\n- [x] Plain task\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    got := entry.Backlog[0]
+    if got.Text != "Renew passport" || got.ID != "x3kq9a" || got.Carried != 3 ||
+        got.Created.Format("2006-01-02") != "2025-12-20" || got.CarriedOn.Format("2006-01-02") != "2025-12-29" {
+        t.Fatalf("unexpected todo: %+v", got)
+    }
+    if FormatTodo(got) != line {
+        t.Fatalf("FormatTodo = %q, want %q", FormatTodo(got), line)
+    }
+
+    // Fields in another order are read too and the line is kept as written.
+    reordered := "- [ ] Renew passport 🆔 x3kq9a [carried:: 3] ➕ 2025-12-20 [carried_on:: 2025-12-29]"
+    entry, err = ParseMarkdown([]byte(strings.Replace(md, line, reordered, 1)))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    if !entry.Backlog[0].Equal(got) {
+        t.Fatalf("reordered fields parsed as %+v", entry.Backlog[0])
+    }
+    entry.Backlog[1].Status = domain.StatusOpen
+    out, err := GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    if !strings.Contains(string(out), reordered+"\n- [ ] Plain task\n") {
+        t.Fatalf("unexpected output:\n%s", out)
+    }
+}
+
+func TestTodoTasksMetadata(t *testing.T) {
+    line := "- [ ] Book flights #project/travel #errand 🆔 f1 ⏫ 🔁 every week when done ➕ 2025-12-20 📅 2025-12-31 ⏳ 2025-12-30"
+    md := "---\ndate: 2025-12-29\n---\n## ✅ Todos – Today\n" + line + "\n- [ ] Plain\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    got := entry.Todos[0]
+    if got.Text != "Book flights #project/travel #errand" || got.Priority != domain.PriorityHigh ||
+        got.Recurrence != "every week when done" || got.Due.Format("2006-01-02") != "2025-12-31" ||
+        len(got.Extra) != 1 || got.Extra[0] != "⏳ 2025-12-30" {
+        t.Fatalf("unexpected todo: %+v", got)
+    }
+    if got.Project() != "travel" || strings.Join(got.Tags(), ",") != "project/travel,errand" {
+        t.Fatalf("tags = %v, project = %q", got.Tags(), got.Project())
+    }
+    if FormatTodo(got) != line {
+        t.Fatalf("FormatTodo = %q, want %q", FormatTodo(got), line)
+    }
+
+    // Unchanged lines are written back as they were; changed ones keep
+    // the fields the journal does not use.
+    out, err := GenerateMarkdown(entry)
+    if err != nil || string(out) != md {
+        t.Fatalf("unchanged entry rewritten (%v):\n%s", err, out)
+    }
+    entry.Todos[0].Status = domain.StatusDone
+    entry.Todos[1].Priority = domain.PriorityLowest
+    entry.Todos[1].Due = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+    out, err = GenerateMarkdown(entry)
+    if err != nil {
+        t.Fatalf("GenerateMarkdown error: %v", err)
+    }
+    want := "- [x]" + strings.TrimPrefix(line, "- [ ]") + "\n- [ ] Plain ⏬ 📅 2026-01-02\n"
+    if !strings.HasSuffix(string(out), want) {
+        t.Fatalf("unexpected output:\n%s\nwant suffix:\n%s", out, want)
+    }
+}
+
+func TestTodoStatus(t *testing.T) {
+    md := "---\ndate: 2025-12-29\n---\n## ✅ Todos – Today\n" +
+        "- [ ] Open\n- [/] Started\n- [x] Done\n- [X] Also done\n- [-] Dropped\n- [>] Later\n- [ ] Old style (partial)\n"
+    entry, err := ParseMarkdown([]byte(md))
+    if err != nil {
+        t.Fatalf("ParseMarkdown error: %v", err)
+    }
+    want := []domain.Status{
+        domain.StatusOpen, domain.StatusInProgress, domain.StatusDone, domain.StatusDone,
+        domain.StatusCancelled, domain.StatusDeferred, domain.StatusInProgress,
+    }
+    if len(entry.Todos) != len(want) {
+        t.Fatalf("parsed %d todos, want %d", len(entry.Todos), len(want))
+    }
+    for i, todo := range entry.Todos {
+        if todo.Status != want[i] {
+            t.Errorf("%q: status %v, want %v", todo.Text, todo.Status, want[i])
+        }
+    }
+    if entry.Todos[6].Text != "Old style" {
+        t.Errorf("partial marker left in text: %q", entry.Todos[6].Text)
+    }
+    for _, todo := range entry.Todos[1:] {
+        line := FormatTodo(todo)
+        if reparsed, ok := parseTodo(line); !ok || !reparsed.Equal(todo) {
+            t.Errorf("%q does not round-trip: %+v", line, reparsed)
+        }
+    }
+    if got := FormatTodo(entry.Todos[6]); got != "- [/] Old style" {
+        t.Errorf("FormatTodo = %q", got)
+    }
+}
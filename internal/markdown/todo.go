@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal-cli/internal/domain"
+)
+
+// Todo metadata is written after the text so that Obsidian can use it:
+// the carry count and date as Dataview inline fields, then the ID,
+// priority, recurrence, creation and due dates with the Obsidian Tasks
+// emoji, which Tasks expects at the end. Other Tasks fields are kept in
+// Todo.Extra. Tags are part of the text.
+//
+//   - [ ] Renew passport #project/travel [carried:: 3] [carried_on:: 2025-12-29] 🆔 x3kq9a ⏫ 🔁 every year ➕ 2025-12-20 📅 2026-01-15
+var todoFields = []struct {
+	re  *regexp.Regexp
+	set func(t *domain.Todo, value string) bool
+}{
+	{regexp.MustCompile(`\s+🆔\s*([\w-]+)$`), func(t *domain.Todo, v string) bool {
+		t.ID = v
+		return true
+	}},
+	{regexp.MustCompile(`\s+➕\s*(\d{4}-\d{2}-\d{2})$`), func(t *domain.Todo, v string) bool {
+		return parseDay(v, &t.Created)
+	}},
+	{regexp.MustCompile(`\s+\[carried::\s*(\d+)\]$`), func(t *domain.Todo, v string) bool {
+		n, err := strconv.Atoi(v)
+		t.Carried = n
+		return err == nil
+	}},
+	{regexp.MustCompile(`\s+\[carried_on::\s*(\d{4}-\d{2}-\d{2})\]$`), func(t *domain.Todo, v string) bool {
+		return parseDay(v, &t.CarriedOn)
+	}},
+	{regexp.MustCompile(`\s+(🔺|⏫|🔼|🔽|⏬)\x{FE0F}?$`), func(t *domain.Todo, v string) bool {
+		t.Priority, _ = domain.PriorityFromSymbol(v)
+		return true
+	}},
+	{regexp.MustCompile(`\s+📅\x{FE0F}?\s*(\d{4}-\d{2}-\d{2})$`), func(t *domain.Todo, v string) bool {
+		return parseDay(v, &t.Due)
+	}},
+	{regexp.MustCompile(`\s+🔁\x{FE0F}?\s*([A-Za-z0-9][A-Za-z0-9 ,;:!-]*)$`), func(t *domain.Todo, v string) bool {
+		t.Recurrence = strings.TrimSpace(v)
+		return true
+	}},
+	// Tasks fields the journal does not use: scheduled, start, done and
+	// cancelled dates, dependencies and the on-completion action.
+	{regexp.MustCompile(`\s+((?:⏳|🛫|✅|❌)\x{FE0F}?\s*\d{4}-\d{2}-\d{2})$`), keepExtra},
+	{regexp.MustCompile(`\s+(⛔\x{FE0F}?\s*[\w,-]+)$`), keepExtra},
+	{regexp.MustCompile(`\s+(🏁\x{FE0F}?\s*\w+)$`), keepExtra},
+}
+
+// keepExtra records a field the journal does not model, in line order.
+func keepExtra(t *domain.Todo, v string) bool {
+	t.Extra = append([]string{v}, t.Extra...)
+	return true
+}
+
+func parseDay(s string, d *time.Time) bool {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return false
+	}
+	*d = t
+	return true
+}
+
+func parseTodo(line string) (domain.Todo, bool) {
+	t := trimCR(line)
+	if !strings.HasPrefix(t, "- [") || len(t) < 5 || t[4] != ']' {
+		return domain.Todo{}, false
+	}
+	status, _ := domain.StatusFromMark(t[3])
+	todo := domain.Todo{Status: status}
+	text := strings.TrimSpace(t[5:])
+	// Fields are taken off the end one at a time, in any order.
+	for found := true; found; {
+		found = false
+		for _, f := range todoFields {
+			m := f.re.FindStringSubmatchIndex(text)
+			if m == nil || !f.set(&todo, text[m[2]:m[3]]) {
+				continue
+			}
+			text, found = text[:m[0]], true
+		}
+	}
+	// Partial progress used to be noted in the text.
+	if stripped, ok := strings.CutSuffix(text, " (partial)"); ok && todo.Status == domain.StatusOpen {
+		text, todo.Status = stripped, domain.StatusInProgress
+	}
+	todo.Text = text
+	return todo, true
+}
+
+// FormatTodo renders todo as a Markdown task line with its metadata.
+func FormatTodo(todo domain.Todo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- [%c] %s", todo.Status.Mark(), todo.Text)
+	if todo.Carried > 0 {
+		fmt.Fprintf(&b, " [carried:: %d]", todo.Carried)
+	}
+	if !todo.CarriedOn.IsZero() {
+		fmt.Fprintf(&b, " [carried_on:: %s]", todo.CarriedOn.Format("2006-01-02"))
+	}
+	if todo.ID != "" {
+		fmt.Fprintf(&b, " 🆔 %s", todo.ID)
+	}
+	if sym := todo.Priority.Symbol(); sym != "" {
+		b.WriteString(" " + sym)
+	}
+	if todo.Recurrence != "" {
+		fmt.Fprintf(&b, " 🔁 %s", todo.Recurrence)
+	}
+	if !todo.Created.IsZero() {
+		fmt.Fprintf(&b, " ➕ %s", todo.Created.Format("2006-01-02"))
+	}
+	if !todo.Due.IsZero() {
+		fmt.Fprintf(&b, " 📅 %s", todo.Due.Format("2006-01-02"))
+	}
+	for _, f := range todo.Extra {
+		b.WriteString(" " + f)
+	}
+	return b.String()
+}
@@ -0,0 +1,234 @@
+// Package review summarises the daily entries of a week, month or year for
+// periodic review entries.
+package review
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
+	"journal-cli/internal/markdown"
+)
+
+// Day is what a review shows of one daily entry.
+type Day struct {
+	Date      time.Time
+	Mood      string
+	Energy    string
+	Highlight string
+}
+
+// Summary is the overview of the daily entries in a period.
+type Summary struct {
+	Period      domain.Period
+	Range       dateexpr.Range
+	Days        []Day    // Daily entries found, in date order
+	Completed   []string // Todos ticked off during the period
+	CarriedOver []string // Todos still open at the end of the period
+	Unreadable  []string // Daily entries that could not be parsed
+}
+
+// Summarize reads the daily entries that l places in journalDir for the
+// period p that contains date.
+func Summarize(fsys fs.FS, journalDir string, l layout.Layout, p domain.Period, date time.Time) (Summary, error) {
+	s := Summary{Period: p, Range: p.Range(date)}
+	var last *domain.JournalEntry
+	for d := s.Range.Start; !d.After(s.Range.End); d = d.AddDate(0, 0, 1) {
+		name := l.Name(d)
+		path := filepath.Join(journalDir, name)
+		if !fsys.Exists(path) {
+			continue
+		}
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return Summary{}, err
+		}
+		entry, err := markdown.ParseMarkdown(data)
+		if err != nil {
+			s.Unreadable = append(s.Unreadable, name)
+			continue
+		}
+		s.Days = append(s.Days, Day{Date: d, Mood: entry.Mood, Energy: entry.Energy, Highlight: entry.Highlight})
+		for _, t := range entry.Todos {
+			if t.Done() && !slices.Contains(s.Completed, t.Text) {
+				s.Completed = append(s.Completed, t.Text)
+			}
+		}
+		last = entry
+	}
+
+	// What the last entry leaves open is carried into the next period.
+	if last != nil {
+		for _, t := range slices.Concat(last.Todos, last.Backlog) {
+			if !t.Closed() && !slices.Contains(s.CarriedOver, t.Text) {
+				s.CarriedOver = append(s.CarriedOver, t.Text)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Point is one step of a trend.
+type Point struct {
+	Label string
+	Value string
+}
+
+// Trend follows a field of the daily entries through the period: day by
+// day in a week, by ISO week in a month and by month in a year. Numeric
+// values ("7", "7/10") are averaged; otherwise the most frequent value is
+// used.
+func (s Summary) Trend(field func(Day) string) []Point {
+	var (
+		points []Point
+		values []string
+	)
+	flush := func(label string) {
+		if v := typical(values); v != "" {
+			points = append(points, Point{Label: label, Value: v})
+		}
+		values = nil
+	}
+	label := ""
+	for _, d := range s.Days {
+		l := s.bucket(d.Date)
+		if l != label && label != "" {
+			flush(label)
+		}
+		label = l
+		if v := strings.TrimSpace(field(d)); v != "" {
+			values = append(values, v)
+		}
+	}
+	if label != "" {
+		flush(label)
+	}
+	return points
+}
+
+// bucket names the step of the trend that date falls in.
+func (s Summary) bucket(date time.Time) string {
+	switch s.Period {
+	case domain.PeriodMonth:
+		_, week := date.ISOWeek()
+		return fmt.Sprintf("W%02d", week)
+	case domain.PeriodYear:
+		return date.Format("Jan")
+	}
+	return date.Format("Mon")
+}
+
+// typical returns the average of values when they are all numeric, or the
+// most frequent one (the first on ties).
+func typical(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if avg, ok := average(values); ok {
+		return strconv.FormatFloat(avg, 'f', -1, 64)
+	}
+	best, bestCount := "", 0
+	for _, v := range values {
+		n := 0
+		for _, w := range values {
+			if strings.EqualFold(v, w) {
+				n++
+			}
+		}
+		if n > bestCount {
+			best, bestCount = v, n
+		}
+	}
+	return best
+}
+
+// average returns the mean of values rounded to one decimal, if they are
+// all numbers.
+func average(values []string) (float64, bool) {
+	var sum float64
+	for _, v := range values {
+		n, ok := number(v)
+		if !ok {
+			return 0, false
+		}
+		sum += n
+	}
+	avg := sum / float64(len(values))
+	return float64(int(avg*10+0.5)) / 10, true
+}
+
+// number reads a value such as "7", "7.5" or "7/10".
+func number(s string) (float64, bool) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "/")
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v, err == nil
+}
+
+// Markdown renders the summary as the body of the review's summary
+// section.
+func (s Summary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Entries: %d of %d days\n", len(s.Days), s.Range.Days())
+	for _, f := range []struct {
+		name  string
+		field func(Day) string
+	}{
+		{"Mood", func(d Day) string { return d.Mood }},
+		{"Energy", func(d Day) string { return d.Energy }},
+	} {
+		trend := s.Trend(f.field)
+		if len(trend) == 0 {
+			continue
+		}
+		steps := make([]string, len(trend))
+		for i, p := range trend {
+			steps[i] = p.Label + " " + p.Value
+		}
+		fmt.Fprintf(&b, "- %s: %s", f.name, strings.Join(steps, " → "))
+		var all []string
+		for _, d := range s.Days {
+			if v := strings.TrimSpace(f.field(d)); v != "" {
+				all = append(all, v)
+			}
+		}
+		if avg, ok := average(all); ok && len(trend) > 1 {
+			fmt.Fprintf(&b, " (average %s)", strconv.FormatFloat(avg, 'f', -1, 64))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "- Todos: %d completed, %d carried over\n", len(s.Completed), len(s.CarriedOver))
+	if len(s.Unreadable) > 0 {
+		fmt.Fprintf(&b, "- Could not read: %s\n", strings.Join(s.Unreadable, ", "))
+	}
+
+	var highlights []string
+	for _, d := range s.Days {
+		if d.Highlight != "" {
+			highlights = append(highlights, fmt.Sprintf("- %s: %s", d.Date.Format("Mon 02 Jan"), d.Highlight))
+		}
+	}
+	list := func(title string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&b, "\n**%s**\n%s\n", title, strings.Join(items, "\n"))
+		}
+	}
+	list("Highlights", highlights)
+	list("Completed", bullets(s.Completed))
+	list("Carried over", bullets(s.CarriedOver))
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func bullets(items []string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = "- " + item
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package review
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
+)
+
+func TestSummarizeMonth(t *testing.T) {
+	mem := fs.NewMemFS()
+	entries := map[string]string{
+		"2025-12-01": "mood: \"4\"\nenergy: \"7/10\"\nhighlight: kickoff",
+		"2025-12-03": "mood: \"6\"\nenergy: \"5/10\"\nhighlight: \"\"",
+		"2025-12-10": "mood: calm\nenergy: \"6\"\nhighlight: demo",
+		"2025-12-11": "mood: calm\nenergy: \"8\"\nhighlight: \"\"",
+		"2025-12-12": "mood: tired\nenergy: \"4\"\nhighlight: \"\"",
+		"2025-11-30": "mood: \"1\"\nenergy: \"1\"\nhighlight: outside the month",
+	}
+	for date, fm := range entries {
+		content := "---\ndate: " + date + "\ntemplate: t\n" + fm + "\n---\n\n## ✅ Todos – Today\n- [x] done " + date + "\n- [ ] open " + date + "\n"
+		if err := mem.WriteFile(filepath.Join("/j", date+".md"), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mem.WriteFile("/j/2025-12-15.md", []byte("not an entry")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Summarize(mem, "/j", layout.Layout{}, domain.PeriodMonth, time.Date(2025, 12, 17, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if len(s.Days) != 5 || len(s.Completed) != 5 {
+		t.Errorf("expected 5 days and 5 completed todos, got %d and %d", len(s.Days), len(s.Completed))
+	}
+	if len(s.CarriedOver) != 1 || s.CarriedOver[0] != "open 2025-12-12" {
+		t.Errorf("only the last entry's open todos carry over, got %v", s.CarriedOver)
+	}
+
+	got := s.Markdown()
+	for _, want := range []string{
+		"- Entries: 5 of 31 days\n",
+		"- Mood: W49 5 → W50 calm\n",
+		"- Energy: W49 6 → W50 6 (average 6)\n",
+		"- Todos: 5 completed, 1 carried over\n",
+		"- Could not read: 2025-12-15.md\n",
+		"**Highlights**\n- Mon 01 Dec: kickoff\n- Wed 10 Dec: demo\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary is missing %q:\n%s", want, got)
+		}
+	}
+}
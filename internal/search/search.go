@@ -0,0 +1,543 @@
+// Package search finds journal entries matching a query such as
+//
+//	migration -postgres "went live" OR mood:tired section:gratitude date:2025-12
+//
+// Words and quoted phrases match any section of an entry, ignoring case.
+// Terms are combined with AND unless joined by OR; NOT or a leading "-"
+// negates a term, and parentheses group them. Field filters narrow the
+// entries (mood:, energy:, highlight:, todo:, template:, date:), while
+// section: limits the sections words and phrases are looked for in.
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/index"
+)
+
+// Query is a parsed search query.
+type Query struct {
+	root     node
+	sections []string         // section: values; empty for every section
+	patterns []*regexp.Regexp // Text looked for and not negated, for snippets
+}
+
+// Section is one searchable part of an entry.
+type Section struct {
+	Name string // Heading shown with results, e.g. "Highlight" or a question title
+	Text string
+	keys []string // Lowercase names section: accepts besides the heading
+}
+
+// Hit is a section that contains a word or phrase of the query.
+type Hit struct {
+	Section string
+	Snippet string // The matching line, shortened around the first match
+}
+
+// Result is an entry that matches a query.
+type Result struct {
+	Entry *domain.JournalEntry
+	Path  string
+	Hits  []Hit
+}
+
+// Sections returns the searchable sections of e in file order.
+func Sections(e *domain.JournalEntry) []Section {
+	var s []Section
+	add := func(name, text string, keys ...string) {
+		if strings.TrimSpace(text) != "" {
+			s = append(s, Section{Name: name, Text: text, keys: keys})
+		}
+	}
+	add("Summary", e.Summary, "summary")
+	add("Mood", e.Mood, "mood")
+	add("Energy", e.Energy, "energy")
+	add("Highlight", e.Highlight, "highlight")
+	add("Todos", todoText(e.Todos), "todos", "todo")
+	add("Backlog", todoText(e.Backlog), "backlog", "todos", "todo")
+	for _, a := range e.Answers {
+		name := a.Question
+		if name == "" {
+			name = a.ID
+		}
+		add(name, a.Text, strings.ToLower(a.ID))
+	}
+	return s
+}
+
+func todoText(todos []domain.Todo) string {
+	lines := make([]string, len(todos))
+	for i, t := range todos {
+		lines[i] = t.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matches reports whether section: value v selects the section: v names
+// one of its keys or is part of its heading.
+func (s Section) matches(v string) bool {
+	return slices.Contains(s.keys, v) || strings.Contains(strings.ToLower(s.Name), v)
+}
+
+// Match reports whether e matches the query and returns the sections that
+// contain its words and phrases.
+func (q *Query) Match(e *domain.JournalEntry) ([]Hit, bool) {
+	var scope []Section
+	for _, s := range Sections(e) {
+		if len(q.sections) == 0 || slices.ContainsFunc(q.sections, s.matches) {
+			scope = append(scope, s)
+		}
+	}
+	if !q.root.match(e, scope) {
+		return nil, false
+	}
+	var hits []Hit
+	for _, s := range scope {
+		if snippet, ok := q.snippet(s.Text); ok {
+			hits = append(hits, Hit{Section: s.Name, Snippet: snippet})
+		}
+	}
+	return hits, true
+}
+
+// snippetWidth is roughly how many characters of context are shown on each
+// side of a match.
+const snippetWidth = 40
+
+// snippet returns the line of text holding the earliest match of the
+// query's words and phrases, cut down to the text around it.
+func (q *Query) snippet(text string) (string, bool) {
+	start, end := -1, -1
+	for _, re := range q.patterns {
+		if loc := re.FindStringIndex(text); loc != nil && (start < 0 || loc[0] < start) {
+			start, end = loc[0], loc[1]
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	lineStart := strings.LastIndex(text[:start], "\n") + 1
+	lineEnd := len(text)
+	if i := strings.Index(text[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+	before, after := text[lineStart:start], text[end:lineEnd]
+	// Cut long lines at a word boundary.
+	if r := []rune(before); len(r) > snippetWidth {
+		before = string(r[len(r)-snippetWidth:])
+		if i := strings.Index(before, " "); i >= 0 {
+			before = before[i+1:]
+		}
+		before = "…" + before
+	}
+	if r := []rune(after); len(r) > snippetWidth {
+		after = string(r[:snippetWidth])
+		if i := strings.LastIndex(after, " "); i >= 0 {
+			after = after[:i]
+		}
+		after += "…"
+	}
+	return strings.TrimSpace(before + text[start:end] + after), true
+}
+
+// Search looks through every entry in the index, daily entries and
+// reviews alike, and returns those matching q, newest first. Files that
+// could not be parsed are returned too; other notes, without a frontmatter
+// date, are ignored.
+func Search(x *index.Index, q *Query) (results []Result, unreadable []string) {
+	candidates := q.root.candidates(x)
+	for _, d := range x.Docs() {
+		path := filepath.Join(x.Dir(), d.Path)
+		switch {
+		case d.Note:
+			continue
+		case d.Entry == nil:
+			unreadable = append(unreadable, path)
+			continue
+		case candidates != nil && !candidates[d]:
+			continue
+		}
+		if hits, ok := q.Match(d.Entry); ok {
+			results = append(results, Result{Entry: d.Entry, Path: path, Hits: hits})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := b.Entry.Date.Compare(a.Entry.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return results, unreadable
+}
+
+// node is an element of a parsed query.
+type node interface {
+	match(e *domain.JournalEntry, scope []Section) bool
+	// candidates narrows down the docs of x that can match using its
+	// word index, nil for any.
+	candidates(x *index.Index) docSet
+}
+
+type docSet = map[*index.Doc]bool
+
+// candidatesOf returns the docs that have every word of s.
+func candidatesOf(x *index.Index, s string) docSet {
+	var set docSet
+	for _, w := range index.Words(s) {
+		docs := x.Containing(w)
+		if set == nil {
+			set = docs
+			continue
+		}
+		for d := range set {
+			if !docs[d] {
+				delete(set, d)
+			}
+		}
+	}
+	return set
+}
+
+type (
+	andNode []node
+	orNode  []node
+	notNode struct{ n node }
+
+	// termNode is a word or phrase looked for in the sections in scope.
+	termNode struct {
+		text string
+		re   *regexp.Regexp
+	}
+
+	// fieldNode matches text in one field of the entry.
+	fieldNode struct {
+		field string
+		text  string
+		re    *regexp.Regexp
+	}
+
+	templateNode string
+	dateNode     dateexpr.Range
+
+	// hasSection matches entries with a section in scope.
+	hasSection struct{}
+)
+
+func (n andNode) candidates(x *index.Index) docSet {
+	var set docSet
+	for _, c := range n {
+		docs := c.candidates(x)
+		switch {
+		case docs == nil:
+		case set == nil:
+			set = docs
+		default:
+			for d := range set {
+				if !docs[d] {
+					delete(set, d)
+				}
+			}
+		}
+	}
+	return set
+}
+
+func (n orNode) candidates(x *index.Index) docSet {
+	set := docSet{}
+	for _, c := range n {
+		docs := c.candidates(x)
+		if docs == nil {
+			return nil
+		}
+		for d := range docs {
+			set[d] = true
+		}
+	}
+	return set
+}
+
+func (notNode) candidates(*index.Index) docSet      { return nil }
+func (templateNode) candidates(*index.Index) docSet { return nil }
+func (dateNode) candidates(*index.Index) docSet     { return nil }
+func (hasSection) candidates(*index.Index) docSet   { return nil }
+
+func (n termNode) candidates(x *index.Index) docSet  { return candidatesOf(x, n.text) }
+func (n fieldNode) candidates(x *index.Index) docSet { return candidatesOf(x, n.text) }
+
+func (n andNode) match(e *domain.JournalEntry, scope []Section) bool {
+	for _, c := range n {
+		if !c.match(e, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) match(e *domain.JournalEntry, scope []Section) bool {
+	for _, c := range n {
+		if c.match(e, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n notNode) match(e *domain.JournalEntry, scope []Section) bool {
+	return !n.n.match(e, scope)
+}
+
+func (n termNode) match(_ *domain.JournalEntry, scope []Section) bool {
+	return slices.ContainsFunc(scope, func(s Section) bool { return n.re.MatchString(s.Text) })
+}
+
+func (n fieldNode) match(e *domain.JournalEntry, _ []Section) bool {
+	var text string
+	switch n.field {
+	case "mood":
+		text = e.Mood
+	case "energy":
+		text = e.Energy
+	case "highlight":
+		text = e.Highlight
+	case "todo":
+		text = todoText(slices.Concat(e.Todos, e.Backlog))
+	}
+	return n.re.MatchString(text)
+}
+
+func (hasSection) match(_ *domain.JournalEntry, scope []Section) bool {
+	return len(scope) > 0
+}
+
+func (n templateNode) match(e *domain.JournalEntry, _ []Section) bool {
+	return strings.EqualFold(e.Template, string(n))
+}
+
+func (n dateNode) match(e *domain.JournalEntry, _ []Section) bool {
+	return dateexpr.Range(n).Contains(e.Date)
+}
+
+// Parse parses a query. Relative dates in date: filters, such as
+// date:"last week", are resolved against now.
+func Parse(query string, now time.Time) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, now: now, q: &Query{}}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].text)
+	}
+	if root == nil {
+		if len(p.q.sections) == 0 {
+			return nil, fmt.Errorf("empty query")
+		}
+		root = hasSection{} // Only section: filters
+	}
+	p.q.root = root
+	return p.q, nil
+}
+
+type token struct {
+	text   string
+	quoted bool // A phrase, never an operator
+}
+
+// tokenize splits a query into words, quoted phrases (also as field
+// values, as in mood:"very tired") and parentheses.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, token{text: string(r)})
+			i++
+		default:
+			var b strings.Builder
+			quoted := false
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '(' && rs[i] != ')' {
+				if rs[i] != '"' {
+					b.WriteRune(rs[i])
+					i++
+					continue
+				}
+				end := slices.Index(rs[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("unclosed quote in query")
+				}
+				b.WriteString(string(rs[i+1 : i+1+end]))
+				i += end + 2
+				quoted = true
+			}
+			tokens = append(tokens, token{text: b.String(), quoted: quoted})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+	q      *Query
+	negate int // Depth of NOT around the term being parsed
+}
+
+func (p *parser) peek(op string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == op
+}
+
+// or parses terms joined by OR. It returns nil when there are none.
+func (p *parser) or() (node, error) {
+	var alts orNode
+	for {
+		n, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			alts = append(alts, n)
+		}
+		if !p.peek("OR") {
+			break
+		}
+		p.pos++
+	}
+	switch len(alts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return alts[0], nil
+	}
+	return alts, nil
+}
+
+func (p *parser) and() (node, error) {
+	var all andNode
+	for p.pos < len(p.tokens) && !p.peek("OR") && !p.peek(")") {
+		if p.peek("AND") {
+			p.pos++
+			continue
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			all = append(all, n)
+		}
+	}
+	switch len(all) {
+	case 0:
+		return nil, nil
+	case 1:
+		return all[0], nil
+	}
+	return all, nil
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.tokens[p.pos]
+	negated := false
+	switch {
+	case p.peek("NOT"):
+		p.pos++
+		negated = true
+	case !t.quoted && len(t.text) > 1 && strings.HasPrefix(t.text, "-"):
+		p.tokens[p.pos].text = t.text[1:]
+		negated = true
+	}
+	if negated {
+		if p.pos >= len(p.tokens) {
+			return nil, fmt.Errorf("NOT without a term in query")
+		}
+		p.negate++
+		n, err := p.unary()
+		p.negate--
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.tokens[p.pos]
+	p.pos++
+	if !t.quoted && t.text == "(" {
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return n, nil
+	}
+	if !t.quoted && t.text == ")" {
+		return nil, fmt.Errorf("unexpected ) in query")
+	}
+
+	if strings.TrimSpace(t.text) == "" {
+		return nil, nil // An empty phrase
+	}
+	if field, value, ok := strings.Cut(t.text, ":"); ok && value != "" {
+		switch field = strings.ToLower(field); field {
+		case "mood", "energy", "highlight", "todo":
+			re := pattern(value)
+			if p.negate == 0 {
+				p.q.patterns = append(p.q.patterns, re)
+			}
+			return fieldNode{field: field, text: value, re: re}, nil
+		case "template":
+			return templateNode(value), nil
+		case "date":
+			r, err := dateexpr.ParseRange(value, p.now)
+			if err != nil {
+				return nil, fmt.Errorf("date: %w", err)
+			}
+			return dateNode(r), nil
+		case "section":
+			if p.negate > 0 {
+				return nil, fmt.Errorf("section: cannot be negated")
+			}
+			p.q.sections = append(p.q.sections, strings.ToLower(value))
+			return nil, nil
+		}
+		// Anything else, such as 10:30, is an ordinary word.
+	}
+	re := pattern(t.text)
+	if p.negate == 0 {
+		p.q.patterns = append(p.q.patterns, re)
+	}
+	return termNode{text: t.text, re: re}, nil
+}
+
+// pattern matches the words of s in order, ignoring case and the amount
+// of space between them.
+func pattern(s string) *regexp.Regexp {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+}
@@ -0,0 +1,137 @@
+package search
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"journal-cli/internal/fs"
+	"journal-cli/internal/index"
+)
+
+// indexEntries writes the entries to /j and returns their index.
+func indexEntries(t *testing.T, entries map[string]string) *index.Index {
+	t.Helper()
+	mem := fs.NewMemFS()
+	for name, content := range entries {
+		if err := mem.WriteFile(filepath.Join("/j", filepath.FromSlash(name)), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x := index.New("/j")
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestSearch(t *testing.T) {
+	x := indexEntries(t, map[string]string{
+		"2025-12-01.md": "---\ndate: 2025-12-01\ntemplate: workday\nmood: tired\nenergy: low\n---\n\n" +
+			"## ✅ Todos – Today\n- [ ] Plan the database migration\n\n" +
+			"## 🧠 What did I learn?\n<!-- question: learned -->\nPostgres locks whole tables on ALTER.\n",
+		"2025-12-02.md": "---\ndate: 2025-12-02\ntemplate: gentle-day\nmood: calm\nhighlight: The migration went live\n---\n\n" +
+			"## 🧠 What am I grateful for?\n<!-- question: gratitude -->\nCoffee with Sam.\nA quiet evening after the migration.\n",
+		"2025/2025-12-03.md": "---\ndate: 2025-12-03\ntemplate: workday\nmood: Tired\n---\n\n" +
+			"## 🧠 What did I learn?\n<!-- question: learned -->\nNothing about databases.\n",
+		"2025-W49.md": "---\ndate: 2025-12-01\nperiod: week\ntemplate: weekly-review\n---\n\n" +
+			"## 🧠 What went well?\n<!-- question: well -->\nThe migration, finally.\n",
+		"notes.md":  "A note about the migration without frontmatter.\n",
+		"ideas.md":  "---\ntags: [migration]\n---\nA note with frontmatter but no date.\n",
+		"broken.md": "---\ndate: 2025-12-32\n---\nAn entry about the migration with a bad date.\n",
+	})
+	now := time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		query string
+		want  []string // Dates of matching entries, newest first; W for the review
+	}{
+		{"migration", []string{"12-02", "12-01", "W"}},
+		{"MIGRATION -postgres", []string{"12-02", "W"}},
+		{`"went live"`, []string{"12-02"}},
+		{`"migration went"`, []string{"12-02"}},
+		{"postgres OR databases", []string{"12-03", "12-01"}},
+		{"mood:tired", []string{"12-03", "12-01"}},
+		{"mood:tired (migration OR coffee)", []string{"12-01"}},
+		{"NOT mood:tired template:gentle-day", []string{"12-02"}},
+		{"template:workday date:2025-12-03", []string{"12-03"}},
+		{`date:"2025-12-02..2025-12-05"`, []string{"12-03", "12-02"}},
+		{"migration section:gratitude", []string{"12-02"}},
+		{"migration section:highlight", []string{"12-02"}},
+		{"locks section:learned", []string{"12-01"}},
+		{"todo:database", []string{"12-01"}},
+		{"section:gratitude", []string{"12-02"}},
+		{"10:30", nil},
+	} {
+		q, err := Parse(tt.query, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		results, unreadable := Search(x, q)
+		if !slices.Equal(unreadable, []string{filepath.Join("/j", "broken.md")}) {
+			t.Fatalf("Search(%q): unreadable %v", tt.query, unreadable)
+		}
+		var got []string
+		for _, r := range results {
+			if r.Entry.Period != "" {
+				got = append(got, "W")
+			} else {
+				got = append(got, r.Entry.Date.Format("01-02"))
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchSnippets(t *testing.T) {
+	long := strings.Repeat("words before ", 10) + "the migration" + strings.Repeat(" and words after", 10)
+	x := indexEntries(t, map[string]string{
+		"2025-12-02.md": "---\ndate: 2025-12-02\nhighlight: The Migration went live\n---\n\n" +
+			"## 🧠 What am I grateful for?\n<!-- question: gratitude -->\nCoffee with Sam.\n" + long + "\n",
+	})
+	q, err := Parse("migration -coffee OR sam", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _ := Search(x, q)
+	if len(results) != 1 {
+		t.Fatalf("Search = %v", results)
+	}
+	hits := results[0].Hits
+	if len(hits) != 2 || hits[0].Section != "Highlight" || hits[0].Snippet != "The Migration went live" {
+		t.Fatalf("hits = %+v", hits)
+	}
+	// The first match in a section wins, on its own line.
+	if hits[1].Section != "What am I grateful for?" || hits[1].Snippet != "Coffee with Sam." {
+		t.Errorf("snippet = %q", hits[1].Snippet)
+	}
+
+	q, _ = Parse("migration section:grateful", time.Now())
+	results, _ = Search(x, q)
+	want := "…before words before words before the migration and words after and words after and…"
+	if len(results) != 1 || len(results[0].Hits) != 1 || results[0].Hits[0].Snippet != want {
+		t.Errorf("long snippet = %+v, want %q", results, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for query, want := range map[string]string{
+		"":                     "empty query",
+		`"unclosed`:            "unclosed quote",
+		"(migration":           "missing )",
+		"migration)":           "unexpected",
+		"date:someday":         "date:",
+		"NOT":                  "NOT without a term",
+		"-section:gratitude x": "cannot be negated",
+	} {
+		_, err := Parse(query, time.Now())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) = %v, want error containing %q", query, err, want)
+		}
+	}
+}
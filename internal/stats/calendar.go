@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"time"
+
+	"journal-cli/internal/index"
+)
+
+// Day is what the calendar shows for a day with an entry.
+type Day struct {
+	Mood   string
+	Energy string
+	Words  int // Words written in answers to the template's questions
+}
+
+// Calendar holds the days with a daily entry, keyed by YYYY-MM-DD.
+type Calendar map[string]Day
+
+// NewCalendar collects the parsed daily entries of an index by date.
+func NewCalendar(entries []index.Daily) Calendar {
+	c := Calendar{}
+	for _, d := range entries {
+		if d.Entry == nil {
+			continue
+		}
+		c[dayKey(d.Date)] = Day{Mood: d.Entry.Mood, Energy: d.Entry.Energy, Words: answerWords(d.Entry)}
+	}
+	return c
+}
+
+// Day returns the entry of date, if there is one.
+func (c Calendar) Day(date time.Time) (Day, bool) {
+	d, ok := c[dayKey(date)]
+	return d, ok
+}
@@ -1,51 +1,81 @@
 package stats
 
 import (
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/index"
 )
 
 // Stats holds journal statistics
 type Stats struct {
-	TotalEntries int
-	LastMissed   time.Time
+	TotalEntries  int
+	LastMissed    time.Time // Latest day before today without an entry
+	CurrentStreak int       // Days in a row with an entry up to today, or yesterday
+	LongestStreak int
 }
 
-// GetStats calculates statistics for the given journal directory.
-func GetStats(journalDir string) (Stats, error) {
+// GetStats calculates statistics for the daily entries of an index, as
+// returned by its Daily method, up to today.
+func GetStats(entries []index.Daily, today time.Time) Stats {
 	var stats Stats
 
 	// 1. Count Total Entries
-	entries, err := os.ReadDir(journalDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return stats, nil
-		}
-		return stats, err
+	stats.TotalEntries = len(entries)
+	days := map[string]bool{}
+	for _, e := range entries {
+		days[dayKey(e.Date)] = true
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			stats.TotalEntries++
-		}
+	// 2. Find Last Missed Date
+	// Go back from yesterday until a day without an entry. We skip today
+	// because the user might just be starting to journal.
+	today = dateexpr.Day(today)
+	stats.LastMissed = today.AddDate(0, 0, -1)
+	for days[dayKey(stats.LastMissed)] {
+		stats.LastMissed = stats.LastMissed.AddDate(0, 0, -1)
 	}
 
-	// 2. Find Last Missed Date
-	// Iterate backwards from yesterday up to 30 days.
-	// We skip today because the user might just be starting to journal.
-	now := time.Now()
-	for i := 1; i <= 30; i++ {
-		date := now.AddDate(0, 0, -i)
-		filename := date.Format("2006-01-02") + ".md"
-		path := filepath.Join(journalDir, filename)
-
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			stats.LastMissed = date
-			break
-		}
+	// 3. Streaks
+	if len(entries) > 0 {
+		s := streaks(days, entries[0].Date, today)
+		stats.CurrentStreak, stats.LongestStreak = s.current, s.longest
 	}
+	return stats
+}
 
-	return stats, nil
+type streakStats struct {
+	current    int
+	longest    int
+	longestEnd time.Time // Last day of the longest streak
+}
+
+// dayKey identifies the day of t in maps of days.
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// streaks finds the runs of days with an entry from start to end. The
+// current streak ends on end, or the day before when end has no entry
+// yet.
+func streaks(days map[string]bool, start, end time.Time) streakStats {
+	var s streakStats
+	run, before := 0, 0 // Runs ending on the day and the day before
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, end.Location())
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		before = run
+		if !days[dayKey(day)] {
+			run = 0
+			continue
+		}
+		run++
+		if run > s.longest {
+			s.longest, s.longestEnd = run, day
+		}
+	}
+	s.current = run
+	if run == 0 {
+		s.current = before
+	}
+	return s
 }
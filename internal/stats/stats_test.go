@@ -0,0 +1,187 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/fs"
+	"journal-cli/internal/index"
+	"journal-cli/internal/layout"
+	"journal-cli/internal/template"
+)
+
+// daily indexes the files in dir and returns the daily entries l names.
+func daily(t *testing.T, mem fs.FS, dir string, l layout.Layout) []index.Daily {
+	t.Helper()
+	x := index.New(dir)
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	return x.Daily(l)
+}
+
+func TestGetStats(t *testing.T) {
+	mem := fs.NewMemFS()
+	dir := filepath.Join("vault", "Journal")
+	for _, d := range []string{"2025-12-26", "2025-12-28", "2025-12-29"} {
+		if err := mem.WriteFile(filepath.Join(dir, d+".md"), []byte("---\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2025, 12, 30, 9, 0, 0, 0, time.UTC)
+	s := GetStats(daily(t, mem, dir, layout.Layout{}), now)
+
+	if s.TotalEntries != 3 {
+		t.Errorf("TotalEntries = %d, want 3", s.TotalEntries)
+	}
+	if got := s.LastMissed.Format("2006-01-02"); got != "2025-12-27" {
+		t.Errorf("LastMissed = %s, want 2025-12-27", got)
+	}
+	if s.CurrentStreak != 2 || s.LongestStreak != 2 {
+		t.Errorf("streaks = %d, %d, want 2, 2", s.CurrentStreak, s.LongestStreak)
+	}
+
+	// Missed days are found however far back, and today's entry extends
+	// the streak.
+	for d := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC); d.Before(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)); d = d.AddDate(0, 0, 1) {
+		if d.Day() == 5 && d.Month() == 10 {
+			continue
+		}
+		if err := mem.WriteFile(filepath.Join(dir, d.Format("2006-01-02")+".md"), []byte("---\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s = GetStats(daily(t, mem, dir, layout.Layout{}), now)
+	if got := s.LastMissed.Format("2006-01-02"); got != "2025-10-05" || s.CurrentStreak != 86 || s.LongestStreak != 86 {
+		t.Errorf("LastMissed = %s, streaks %d, %d; want 2025-10-05, 86, 86", got, s.CurrentStreak, s.LongestStreak)
+	}
+}
+
+func TestGetStatsNestedLayout(t *testing.T) {
+	mem := fs.NewMemFS()
+	dir := filepath.Join("vault", "Journal")
+	for _, name := range []string{"2025/12/2025-12-29 Monday.md", "2025/12/2025-12-28 Sunday.md", "2025-W52.md", "2025/notes.md"} {
+		if err := mem.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("---\n---\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := layout.Parse("YYYY/MM/YYYY-MM-DD dddd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := GetStats(daily(t, mem, dir, l), time.Date(2025, 12, 30, 9, 0, 0, 0, time.UTC))
+	if s.TotalEntries != 2 {
+		t.Errorf("TotalEntries = %d, want 2", s.TotalEntries)
+	}
+	if got := s.LastMissed.Format("2006-01-02"); got != "2025-12-27" {
+		t.Errorf("LastMissed = %s, want 2025-12-27", got)
+	}
+}
+
+func TestGetStatsMissingDir(t *testing.T) {
+	s := GetStats(daily(t, fs.NewMemFS(), "nope", layout.Layout{}), time.Now())
+	if s.TotalEntries != 0 {
+		t.Errorf("TotalEntries = %d, want 0", s.TotalEntries)
+	}
+}
+
+func TestNewCalendar(t *testing.T) {
+	mem := fs.NewMemFS()
+	dir := filepath.Join("vault", "Journal")
+	files := map[string]string{
+		"2025-12-28.md": "---\ndate: 2025-12-28\nmood: calm\nenergy: high\n---\n\n" +
+			"## 🧠 What did I learn?\n<!-- question: learned -->\nClocks are hard.\n\n" +
+			"## 🧠 Grateful for\n<!-- question: gratitude -->\nCoffee.\n",
+		"2025-12-29.md": "not an entry",
+		"2025-W52.md":   "---\ndate: 2025-12-22\nperiod: week\n---\n",
+	}
+	for name, content := range files {
+		if err := mem.WriteFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x := index.New(dir)
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalendar(x.Daily(layout.Layout{}))
+	if len(c) != 1 {
+		t.Errorf("calendar = %v, want one day", c)
+	}
+	d, ok := c.Day(time.Date(2025, 12, 28, 18, 0, 0, 0, time.Local))
+	if want := (Day{Mood: "calm", Energy: "high", Words: 4}); !ok || d != want {
+		t.Errorf("Day(2025-12-28) = %+v, %v, want %+v", d, ok, want)
+	}
+	if _, ok := c.Day(time.Date(2025, 12, 29, 0, 0, 0, 0, time.Local)); ok {
+		t.Error("unparsable entry on the calendar")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	mem := fs.NewMemFS()
+	dir := filepath.Join("vault", "Journal")
+	entry := func(date, mood, answers, todos string) string {
+		return "---\ndate: " + date + "\ntemplate: work\nmood: " + mood + "\n---\n\n" +
+			"## ✅ Todos – Today\n" + todos + "\n" + answers
+	}
+	learned := "## 🧠 What did I learn?\n<!-- question: learned -->\nClocks are hard.\n"
+	files := map[string]string{
+		"2025-11-29.md": entry("2025-11-29", "calm", learned, "- [x] Ship\n- [ ] Test\n- [-] Drop\n"),
+		"2025-11-30.md": entry("2025-11-30", "calm", "", "- [x] Review\n"),
+		"2025-12-01.md": entry("2025-12-01", "tired", learned+"## 🧠 Why?\n<!-- question: why -->\nLate night\n", ""),
+		"2025-12-03.md": entry("2025-12-03", "tired", "", "- [ ] Rest\n"),
+		"2025-12-04.md": "not an entry",
+	}
+	for name, content := range files {
+		if err := mem.WriteFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x := index.New(dir)
+	if err := x.Update(mem); err != nil {
+		t.Fatal(err)
+	}
+	templates := []template.Template{{Name: "work", Questions: []template.Question{
+		{ID: "learned", Title: "What did I learn?", Required: true},
+		{ID: "why", Title: "Why?", Required: true, ShowIf: "mood == tired"},
+	}}}
+	today := time.Date(2025, 12, 3, 20, 0, 0, 0, time.Local)
+
+	s := Summarize(x.Daily(layout.Layout{}), templates, dateexpr.Range{}, today)
+	if got := s.Start.Format("2006-01-02") + ".." + s.End.Format("2006-01-02"); got != "2025-11-29..2025-12-03" || s.Days != 5 {
+		t.Errorf("range = %s, %d days", got, s.Days)
+	}
+	if s.Entries != 4 || s.CurrentStreak != 1 || s.LongestStreak != 3 || s.LongestStreakEnd.Day() != 1 {
+		t.Errorf("entries %d, streaks %d, %d to %s", s.Entries, s.CurrentStreak, s.LongestStreak, s.LongestStreakEnd)
+	}
+	// learned on every entry, why on the tired ones.
+	if s.RequiredAsked != 6 || s.RequiredAnswered != 3 {
+		t.Errorf("required %d of %d, want 3 of 6", s.RequiredAnswered, s.RequiredAsked)
+	}
+	if s.TodosPlanned != 4 || s.TodosDone != 2 || s.Words != 8 || s.AverageWords() != 2 {
+		t.Errorf("todos %d of %d, words %d", s.TodosDone, s.TodosPlanned, s.Words)
+	}
+	if len(s.Months) != 2 || s.Months[0].Days != 2 || s.Months[0].Entries != 2 || s.Months[0].TodoRate() != 2.0/3 ||
+		s.Months[1].Days != 3 || s.Months[1].TodosPlanned != 1 {
+		t.Errorf("months = %+v", s.Months)
+	}
+	if got := s.PerWeek(); got != 4*7/5.0 {
+		t.Errorf("PerWeek = %v", got)
+	}
+
+	// A range limits the entries counted; the current streak ends on its
+	// last day.
+	span, err := dateexpr.ParseRange("2025-11", today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = Summarize(x.Daily(layout.Layout{}), templates, span, today)
+	if s.Days != 30 || s.Entries != 2 || s.CurrentStreak != 2 || len(s.Months) != 1 || s.PerMonth() != 2 {
+		t.Errorf("November: %d days, %d entries, streak %d, %d months, %v per month", s.Days, s.Entries, s.CurrentStreak, len(s.Months), s.PerMonth())
+	}
+}
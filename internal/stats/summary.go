@@ -0,0 +1,199 @@
+package stats
+
+import (
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/index"
+	"journal-cli/internal/template"
+)
+
+// Summary is the statistics of the daily entries in a range of days.
+type Summary struct {
+	Start, End time.Time // The range, ending today at the latest
+	Days       int       // Days in the range
+	Entries    int
+
+	CurrentStreak    int // Days in a row with an entry up to End, or the day before
+	LongestStreak    int
+	LongestStreakEnd time.Time
+
+	Words int // Written in answers to the template's questions
+
+	// Required questions asked in the entries, given their answers, and
+	// how many of them were answered. Entries whose template is not found
+	// are not counted.
+	RequiredAsked    int
+	RequiredAnswered int
+
+	// Todos on the entries' lists, not counting cancelled ones, and how
+	// many of them were done.
+	TodosPlanned int
+	TodosDone    int
+
+	Months []Month // Each calendar month of the range, oldest first
+}
+
+// Month is the part of a Summary in one calendar month.
+type Month struct {
+	Start        time.Time // First day of the month
+	Days         int       // Days of the month in the range
+	Entries      int
+	Words        int
+	TodosPlanned int
+	TodosDone    int
+}
+
+// PerWeek returns the average number of entries in seven days.
+func (s Summary) PerWeek() float64 {
+	return ratio(s.Entries*7, s.Days)
+}
+
+// PerMonth returns the average number of entries in a month, counting
+// the months partly in the range by their share of days.
+func (s Summary) PerMonth() float64 {
+	months := 0.0
+	for _, m := range s.Months {
+		months += float64(m.Days) / float64(daysIn(m.Start))
+	}
+	if months == 0 {
+		return 0
+	}
+	return float64(s.Entries) / months
+}
+
+// AverageWords returns the average number of words written per entry.
+func (s Summary) AverageWords() float64 {
+	return ratio(s.Words, s.Entries)
+}
+
+// RequiredRate returns the share of asked required questions answered.
+func (s Summary) RequiredRate() float64 {
+	return ratio(s.RequiredAnswered, s.RequiredAsked)
+}
+
+// TodoRate returns the share of planned todos done.
+func (s Summary) TodoRate() float64 {
+	return ratio(s.TodosDone, s.TodosPlanned)
+}
+
+// TodoRate returns the share of the month's planned todos done.
+func (m Month) TodoRate() float64 {
+	return ratio(m.TodosDone, m.TodosPlanned)
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func daysIn(month time.Time) int {
+	return month.AddDate(0, 1, -month.Day()).Day()
+}
+
+// Summarize computes the statistics of the daily entries within span,
+// the zero Range for every entry up to today. templates are looked up by
+// the entries' template names to find their required questions.
+func Summarize(entries []index.Daily, templates []template.Template, span dateexpr.Range, today time.Time) Summary {
+	today = dateexpr.Day(today)
+	s := Summary{Start: span.Start, End: span.End}
+	if span.End.IsZero() || span.End.After(today) {
+		s.End = today
+	}
+	if span.Start.IsZero() {
+		s.Start = today
+		if len(entries) > 0 && entries[0].Date.Before(today) {
+			s.Start = dateexpr.Day(entries[0].Date)
+		}
+	}
+	if s.Start.After(s.End) {
+		return s
+	}
+
+	byName := map[string]template.Template{}
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+	for m := s.Start.AddDate(0, 0, 1-s.Start.Day()); !m.After(s.End); m = m.AddDate(0, 1, 0) {
+		first, last := m, m.AddDate(0, 1, -1)
+		if first.Before(s.Start) {
+			first = s.Start
+		}
+		if last.After(s.End) {
+			last = s.End
+		}
+		days := int(last.Sub(first).Hours()/24+0.5) + 1
+		s.Days += days
+		s.Months = append(s.Months, Month{Start: m, Days: days})
+	}
+
+	days := map[string]bool{}
+	for _, d := range entries {
+		if key := dayKey(d.Date); d.Entry == nil || key < dayKey(s.Start) || key > dayKey(s.End) {
+			continue
+		}
+		days[dayKey(d.Date)] = true
+		month := &s.Months[monthsBetween(s.Months[0].Start, d.Date)]
+		words := answerWords(d.Entry)
+		planned, done := todoCounts(d.Entry.Todos)
+
+		s.Entries++
+		s.Words += words
+		s.TodosPlanned += planned
+		s.TodosDone += done
+		month.Entries++
+		month.Words += words
+		month.TodosPlanned += planned
+		month.TodosDone += done
+
+		if t, ok := byName[d.Entry.Template]; ok {
+			visible := t.VisibleFor(d.Entry)
+			for i, q := range t.Questions {
+				if !q.Required || !visible[i] {
+					continue
+				}
+				s.RequiredAsked++
+				if answer, _ := d.Entry.Answer(q.Key()); strings.TrimSpace(answer) != "" {
+					s.RequiredAnswered++
+				}
+			}
+		}
+	}
+
+	st := streaks(days, s.Start, s.End)
+	s.CurrentStreak, s.LongestStreak, s.LongestStreakEnd = st.current, st.longest, st.longestEnd
+	return s
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+// answerWords counts the words written in answers to e's questions.
+func answerWords(e *domain.JournalEntry) int {
+	n := 0
+	for _, a := range e.Answers {
+		n += len(strings.Fields(a.Text))
+	}
+	return n
+}
+
+// todoCounts counts the todos of a list that were not cancelled, and
+// those of them done.
+func todoCounts(todos []domain.Todo) (planned, done int) {
+	for _, t := range todos {
+		switch t.Status {
+		case domain.StatusCancelled:
+		case domain.StatusDone:
+			planned++
+			done++
+		default:
+			planned++
+		}
+	}
+	return planned, done
+}
@@ -0,0 +1,270 @@
+package template
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"journal-cli/internal/fs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BlocksDir is the folder inside the templates directory that holds
+// question blocks: YAML files with a questions list, and optionally an
+// include list of their own, that templates pull in by file name.
+//
+//	# blocks/check-in.yaml
+//	questions:
+//	  - id: mood
+//	    title: How am I feeling?
+//
+//	# daily.yaml
+//	name: daily
+//	extends: base
+//	include: [check-in]
+//	questions:
+//	  - id: win
+//	    title: One win today
+//
+// A template asks the questions of the template it extends, then those of
+// each included block, then its own. A question whose key matches an
+// earlier one replaces it in place, so a variant can reword or retype a
+// question without moving it.
+const BlocksDir = "blocks"
+
+// Block is a reusable list of questions stored in BlocksDir.
+type Block struct {
+	Description string     `yaml:"description"`
+	Include     []string   `yaml:"include"`
+	Questions   []Question `yaml:"questions"`
+}
+
+// origin is where a question is defined.
+type origin struct {
+	file string
+	node *yaml.Node
+}
+
+// source is a parsed template or block file.
+type source struct {
+	path     string
+	block    bool
+	root     *yaml.Node
+	t        Template // Name is empty for blocks
+	diags    []Diagnostic
+	ok       bool // Parsed without fatal problems
+	reported bool // diags have been added to the resolver's
+}
+
+// label names the source in cycle errors.
+func (s *source) label() string {
+	if s.block {
+		return BlocksDir + "/" + strings.TrimSuffix(filepath.Base(s.path), filepath.Ext(s.path))
+	}
+	return s.t.Name
+}
+
+type result struct {
+	t  Template
+	ok bool
+}
+
+// resolver expands extends and include, loading the files they refer to on
+// demand.
+type resolver struct {
+	fsys  fs.FS
+	files map[string]*source // By path
+	names map[string]*source // Templates by name
+	done  map[*source]result
+	diags []Diagnostic
+}
+
+func newResolver(fsys fs.FS) *resolver {
+	return &resolver{
+		fsys:  fsys,
+		files: map[string]*source{},
+		names: map[string]*source{},
+		done:  map[*source]result{},
+	}
+}
+
+// add records a problem, once: a broken block or base template is reported
+// only the first time a template uses it.
+func (r *resolver) add(file string, n *yaml.Node, fatal bool, format string, args ...any) {
+	line, col := position(n)
+	d := Diagnostic{File: file, Line: line, Column: col, Message: fmt.Sprintf(format, args...), Fatal: fatal}
+	if !slices.Contains(r.diags, d) {
+		r.diags = append(r.diags, d)
+	}
+}
+
+// report adds the problems found while parsing s.
+func (r *resolver) report(s *source) {
+	if !s.reported {
+		r.diags = append(r.diags, s.diags...)
+		s.reported = true
+	}
+}
+
+// parse reads and checks the file at path, once.
+func (r *resolver) parse(path string, block bool) *source {
+	if s, ok := r.files[path]; ok {
+		return s
+	}
+	s := &source{path: path, block: block}
+	r.files[path] = s
+	data, err := r.fsys.ReadFile(path)
+	if err != nil {
+		s.diags = []Diagnostic{{File: path, Message: err.Error(), Fatal: true}}
+		return s
+	}
+	t, root, diags := checkFile(path, data, block)
+	s.root, s.diags = root, diags
+	if t != nil {
+		s.t = *t
+		s.ok = !hasFatal(diags)
+	}
+	return s
+}
+
+// template resolves the template in s and checks what can only be checked
+// once its questions are known.
+func (r *resolver) template(s *source) (Template, bool) {
+	t, ok := r.resolve(s, nil)
+	if !ok {
+		return Template{}, false
+	}
+	if len(t.Questions) == 0 {
+		r.add(s.path, s.root, false, "template has no questions")
+	}
+	for i, q := range t.Questions {
+		err := t.checkCondition(i)
+		if err == nil {
+			continue
+		}
+		if q.origin.file != s.path {
+			err = fmt.Errorf("%w (in template %q)", err, t.Name)
+		}
+		r.add(q.origin.file, valueOr(q.origin.node, "show_if"), true, "%v", err)
+		ok = false
+	}
+	return t, ok
+}
+
+// resolve expands the extends and include of s. stack holds the sources
+// being resolved, to detect cycles.
+func (r *resolver) resolve(s *source, stack []*source) (Template, bool) {
+	if res, done := r.done[s]; done {
+		return res.t, res.ok
+	}
+	t, ok := s.t, s.ok
+	if ok {
+		stack = append(stack, s)
+		var questions []Question
+		if s.t.Extends != "" {
+			parent, pok := r.expand(s, r.lookupTemplate(s.t.Extends, s), stack, valueOr(s.root, "extends"),
+				fmt.Sprintf("extended template %q", s.t.Extends))
+			questions = parent.Questions
+			t.Description = cmp.Or(t.Description, parent.Description)
+			t.Period = cmp.Or(t.Period, parent.Period)
+			ok = ok && pok
+		}
+		includes := mappingValue(s.root, "include")
+		for i, name := range s.t.Include {
+			node := includes
+			if i < len(includes.Content) {
+				node = includes.Content[i]
+			}
+			block, bok := r.expand(s, r.lookupBlock(name, s), stack, node,
+				fmt.Sprintf("included block %q", name))
+			questions = merge(questions, block.Questions)
+			ok = ok && bok
+		}
+		t.Questions = merge(questions, s.t.Questions)
+	}
+	r.done[s] = result{t, ok}
+	return t, ok
+}
+
+// expand resolves dep, which s refers to at node as what.
+func (r *resolver) expand(s, dep *source, stack []*source, node *yaml.Node, what string) (Template, bool) {
+	switch {
+	case dep == nil:
+		r.add(s.path, node, true, "%s not found", what)
+	case slices.Contains(stack, dep):
+		var cycle []string
+		for _, c := range stack[slices.Index(stack, dep):] {
+			cycle = append(cycle, c.label())
+		}
+		r.add(s.path, node, true, "%s forms a cycle: %s -> %s", what, strings.Join(cycle, " -> "), dep.label())
+	default:
+		r.report(dep)
+		if t, ok := r.resolve(dep, stack); ok {
+			return t, true
+		}
+		r.add(s.path, node, true, "%s has errors", what)
+	}
+	return Template{}, false
+}
+
+// lookupTemplate finds the template called name. When it is not among the
+// files being validated, the templates next to from are searched too, so a
+// single file can be checked on its own.
+func (r *resolver) lookupTemplate(name string, from *source) *source {
+	if s, ok := r.names[name]; ok {
+		return s
+	}
+	dir := filepath.Dir(from.path)
+	files, err := r.fsys.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, file := range files {
+		if file.IsDir() || !isTemplateFile(file.Name()) {
+			continue
+		}
+		if s := r.parse(filepath.Join(dir, file.Name()), false); s.t.Name == name {
+			if s.ok {
+				r.names[name] = s
+			}
+			return s
+		}
+	}
+	return nil
+}
+
+// lookupBlock finds the block called name in the BlocksDir next to the
+// template from, or next to the block from.
+func (r *resolver) lookupBlock(name string, from *source) *source {
+	if name == "" || filepath.Base(name) != name {
+		return nil
+	}
+	dir := filepath.Dir(from.path)
+	if !from.block {
+		dir = filepath.Join(dir, BlocksDir)
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		if path := filepath.Join(dir, name+ext); r.fsys.Exists(path) {
+			return r.parse(path, true)
+		}
+	}
+	return nil
+}
+
+// merge appends questions to base. A question whose key matches one
+// already there replaces it in place.
+func merge(base, questions []Question) []Question {
+	out := slices.Clone(base)
+	for _, q := range questions {
+		i := slices.IndexFunc(out, func(p Question) bool { return p.Key() == q.Key() })
+		if i < 0 {
+			out = append(out, q)
+		} else {
+			out[i] = q
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"journal-cli/internal/domain"
+)
+
+// Condition is a parsed show_if expression, "<ref> <op> <value>", where ref
+// is the ID of an earlier question or one of the entry fields in
+// EntryFields. A bare "<ref>" holds when that answer is set and not "No".
+type Condition struct {
+	Ref   string
+	Op    string // "", "==", "!=", "<", "<=", ">", ">=" or "contains"
+	Value string
+}
+
+// EntryFields are the entry fields a condition can refer to besides
+// question IDs.
+var EntryFields = []string{"mood", "energy", "highlight"}
+
+var conditionRe = regexp.MustCompile(`^\s*([\w.-]+)\s*(?:(<=|>=|!=|==|=|<|>|≤|≥|≠|\bcontains\b)\s*(.*?))?\s*$`)
+
+// ParseCondition parses a show_if expression.
+func ParseCondition(expr string) (Condition, error) {
+	m := conditionRe.FindStringSubmatch(expr)
+	if m == nil {
+		return Condition{}, fmt.Errorf("invalid show_if %q: want \"<id> <op> <value>\"", expr)
+	}
+	c := Condition{Ref: m[1], Op: m[2], Value: strings.Trim(m[3], `"'`)}
+	switch c.Op {
+	case "=":
+		c.Op = "=="
+	case "≤":
+		c.Op = "<="
+	case "≥":
+		c.Op = ">="
+	case "≠":
+		c.Op = "!="
+	}
+	if c.Op != "" && c.Value == "" {
+		return Condition{}, fmt.Errorf("invalid show_if %q: missing value after %s", expr, c.Op)
+	}
+	if c.Op == "" && m[3] != "" {
+		return Condition{}, fmt.Errorf("invalid show_if %q: want \"<id> <op> <value>\"", expr)
+	}
+	return c, nil
+}
+
+// Holds reports whether the condition is met by answer, the current answer
+// to c.Ref. Ordering operators compare numbers and fail when either side
+// is not a number.
+func (c Condition) Holds(answer string) bool {
+	answer = strings.TrimSpace(answer)
+	switch c.Op {
+	case "":
+		return answer != "" && answer != No
+	case "contains":
+		return strings.Contains(strings.ToLower(answer), strings.ToLower(c.Value))
+	}
+
+	a, aok := leadingNumber(answer)
+	v, vok := leadingNumber(c.Value)
+	switch c.Op {
+	case "==", "!=":
+		equal := strings.EqualFold(answer, c.Value)
+		if aok && vok {
+			equal = a == v
+		}
+		return equal == (c.Op == "==")
+	}
+	if !aok || !vok {
+		return false
+	}
+	switch c.Op {
+	case "<":
+		return a < v
+	case "<=":
+		return a <= v
+	case ">":
+		return a > v
+	case ">=":
+		return a >= v
+	}
+	return false
+}
+
+// leadingNumber reads the number at the start of an answer such as "3",
+// "7/10" or "7.5 h".
+func leadingNumber(s string) (float64, bool) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "/")
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	return v, err == nil
+}
+
+// Visible reports whether question i is asked, given answer, which returns
+// the current answer to a question ID or the value of an entry field.
+func (t Template) Visible(i int, answer func(ref string) string) bool {
+	q := t.Questions[i]
+	if q.ShowIf == "" {
+		return true
+	}
+	c, err := ParseCondition(q.ShowIf)
+	if err != nil {
+		return true
+	}
+	return c.Holds(answer(c.Ref))
+}
+
+// VisibleFor reports which questions are asked given the answers and
+// fields of e. A hidden question counts as unanswered for the conditions
+// of later questions.
+func (t Template) VisibleFor(e *domain.JournalEntry) []bool {
+	visible := make([]bool, len(t.Questions))
+	answerOf := func(ref string) string {
+		for i, q := range t.Questions {
+			if q.Key() == ref {
+				if !visible[i] {
+					return ""
+				}
+				answer, _ := e.Answer(ref)
+				return answer
+			}
+		}
+		switch ref {
+		case "mood":
+			return e.Mood
+		case "energy":
+			return e.Energy
+		case "highlight":
+			return e.Highlight
+		}
+		return ""
+	}
+	for i := range t.Questions {
+		visible[i] = t.Visible(i, answerOf)
+	}
+	return visible
+}
+
+// checkCondition reports a show_if expression of question i that does not
+// parse or that refers to anything but an earlier question or an entry
+// field.
+func (t Template) checkCondition(i int) error {
+	q := t.Questions[i]
+	if q.ShowIf == "" {
+		return nil
+	}
+	c, err := ParseCondition(q.ShowIf)
+	if err != nil {
+		return fmt.Errorf("question %q: %w", q.Title, err)
+	}
+	// Question IDs take precedence over entry fields of the same name.
+	isRef := func(p Question) bool { return p.Key() == c.Ref }
+	switch {
+	case slices.ContainsFunc(t.Questions[:i], isRef):
+	case slices.ContainsFunc(t.Questions[i:], isRef):
+		return fmt.Errorf("question %q: show_if refers to %q, which is not an earlier question", q.Title, c.Ref)
+	case !slices.Contains(EntryFields, c.Ref):
+		return fmt.Errorf("question %q: show_if refers to unknown question %q", q.Title, c.Ref)
+	}
+	return nil
+}
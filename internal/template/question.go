@@ -0,0 +1,226 @@
+package template
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QuestionType selects the widget a question is asked with and how its
+// answer is written to the entry.
+type QuestionType string
+
+const (
+	TypeText        QuestionType = "text"        // Free text (the default)
+	TypeScale       QuestionType = "scale"       // Whole number between min and max, "7/10"
+	TypeChoice      QuestionType = "choice"      // One of options
+	TypeMultiChoice QuestionType = "multichoice" // Any of options, as bullet items
+	TypeBoolean     QuestionType = "boolean"     // "Yes" or "No"
+	TypeNumber      QuestionType = "number"      // Decimal number with an optional unit, "7.5 h"
+	TypeList        QuestionType = "list"        // Bullet items
+)
+
+// Answers of these types are also written to the "metrics" frontmatter map
+// so they can be charted.
+var numericTypes = []QuestionType{TypeScale, TypeNumber, TypeBoolean}
+
+// Boolean answers as written to the entry.
+const (
+	Yes = "Yes"
+	No  = "No"
+)
+
+// Kind returns the question type, defaulting to TypeText.
+func (q Question) Kind() QuestionType {
+	if q.Type == "" {
+		return TypeText
+	}
+	return q.Type
+}
+
+// ScaleRange returns the bounds of a scale question, 1 to 10 by default.
+func (q Question) ScaleRange() (lo, hi int) {
+	lo, hi = 1, 10
+	if q.Min != nil {
+		lo = int(*q.Min)
+	}
+	if q.Max != nil {
+		hi = int(*q.Max)
+	}
+	return lo, hi
+}
+
+// Choices returns the options offered by a choice, multichoice or boolean
+// question.
+func (q Question) Choices() []string {
+	if q.Kind() == TypeBoolean {
+		return []string{Yes, No}
+	}
+	return q.Options
+}
+
+// Check reports problems with the question definition itself.
+func (q Question) Check() error {
+	switch q.Kind() {
+	case TypeText, TypeBoolean, TypeList:
+	case TypeChoice, TypeMultiChoice:
+		if len(q.Options) == 0 {
+			return fmt.Errorf("question %q: %s needs options", q.Title, q.Kind())
+		}
+	case TypeScale:
+		if (q.Min != nil && *q.Min != math.Trunc(*q.Min)) || (q.Max != nil && *q.Max != math.Trunc(*q.Max)) {
+			return fmt.Errorf("question %q: scale bounds must be whole numbers", q.Title)
+		}
+		lo, hi := q.ScaleRange()
+		if lo >= hi {
+			return fmt.Errorf("question %q: scale min must be below max", q.Title)
+		}
+		for v := range q.Labels {
+			if v < lo || v > hi {
+				return fmt.Errorf("question %q: label for %d is outside %d-%d", q.Title, v, lo, hi)
+			}
+		}
+	case TypeNumber:
+		if q.Min != nil && q.Max != nil && *q.Min > *q.Max {
+			return fmt.Errorf("question %q: min must not be above max", q.Title)
+		}
+	default:
+		return fmt.Errorf("question %q: unknown type %q", q.Title, q.Type)
+	}
+	if err := q.Validate(q.Default); err != nil {
+		return fmt.Errorf("question %q: default: %w", q.Title, err)
+	}
+	return nil
+}
+
+// Validate reports whether text is an acceptable answer. An empty answer
+// is always accepted.
+func (q Question) Validate(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	switch q.Kind() {
+	case TypeScale:
+		lo, hi := q.ScaleRange()
+		v, ok := ParseScale(text)
+		if !ok || v < lo || v > hi {
+			return fmt.Errorf("enter a whole number from %d to %d", lo, hi)
+		}
+	case TypeNumber:
+		v, ok := q.ParseNumber(text)
+		if !ok {
+			return fmt.Errorf("enter a number")
+		}
+		if q.Min != nil && v < *q.Min {
+			return fmt.Errorf("enter a number of at least %s", FormatNumber(*q.Min))
+		}
+		if q.Max != nil && v > *q.Max {
+			return fmt.Errorf("enter a number of at most %s", FormatNumber(*q.Max))
+		}
+	case TypeChoice, TypeBoolean:
+		if !slices.Contains(q.Choices(), text) {
+			return fmt.Errorf("choose one of: %s", strings.Join(q.Choices(), ", "))
+		}
+	case TypeMultiChoice:
+		for _, item := range ParseItems(text) {
+			if !slices.Contains(q.Options, item) {
+				return fmt.Errorf("%q is not one of: %s", item, strings.Join(q.Options, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Metric returns the numeric value of a scale, number or boolean answer.
+func (q Question) Metric(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	switch q.Kind() {
+	case TypeScale:
+		v, ok := ParseScale(text)
+		return float64(v), ok
+	case TypeNumber:
+		return q.ParseNumber(text)
+	case TypeBoolean:
+		switch text {
+		case Yes:
+			return 1, true
+		case No:
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// IsNumeric reports whether answers to q are recorded as metrics.
+func (q Question) IsNumeric() bool {
+	return slices.Contains(numericTypes, q.Kind())
+}
+
+// FormatScale renders a scale answer, e.g. "7/10".
+func (q Question) FormatScale(v int) string {
+	_, hi := q.ScaleRange()
+	return fmt.Sprintf("%d/%d", v, hi)
+}
+
+// ParseScale reads the value of a scale answer ("7/10" or "7").
+func ParseScale(text string) (int, bool) {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "/")
+	v, err := strconv.Atoi(strings.TrimSpace(text))
+	return v, err == nil
+}
+
+// FormatNumber renders v without trailing zeros.
+func FormatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// FormatNumberAnswer renders a number answer with the question's unit.
+func (q Question) FormatNumberAnswer(v float64) string {
+	if q.Unit == "" {
+		return FormatNumber(v)
+	}
+	return FormatNumber(v) + " " + q.Unit
+}
+
+// ParseNumber reads the value of a number answer, ignoring the unit.
+func (q Question) ParseNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if q.Unit != "" {
+		text = strings.TrimSpace(strings.TrimSuffix(text, q.Unit))
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// FormatItems renders list and multichoice answers as bullet items.
+func FormatItems(items []string) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			lines = append(lines, "- "+item)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseItems reads the bullet items of a list or multichoice answer. Lines
+// without a bullet count as items too.
+func ParseItems(text string) []string {
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		for _, bullet := range []string{"- ", "* ", "+ "} {
+			line = strings.TrimPrefix(line, bullet)
+		}
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
@@ -2,86 +2,93 @@ package template
 
 import (
 	"embed"
-	"os"
-	"path/filepath"
+	"fmt"
 
+	"journal-cli/internal/domain"
 	"journal-cli/internal/fs"
-
-	"gopkg.in/yaml.v3"
 )
 
-//go:embed defaults/*.yaml
+//go:embed defaults/*.yaml defaults/blocks/*.yaml
 var defaultTemplatesFS embed.FS
 
 type Question struct {
-	ID    string `yaml:"id"`
-	Title string `yaml:"title"`
+	ID    string       `yaml:"id"`
+	Title string       `yaml:"title"`
+	Type  QuestionType `yaml:"type"` // Empty means text; see question.go
+
+	Options []string       `yaml:"options"` // choice, multichoice
+	Min     *float64       `yaml:"min"`     // scale (default 1), number
+	Max     *float64       `yaml:"max"`     // scale (default 10), number
+	Labels  map[int]string `yaml:"labels"`  // scale: text shown next to a value
+	Unit    string         `yaml:"unit"`    // number
+
+	Required    bool   `yaml:"required"`    // The flow cannot finish while it is blank
+	Default     string `yaml:"default"`     // Answer prefilled when there is none yet
+	Placeholder string `yaml:"placeholder"` // Hint shown in an empty text, number or list input
+	ShowIf      string `yaml:"show_if"`     // Condition for asking the question; see Condition
+
+	origin origin // Where the question is defined, for diagnostics
 }
 
-type Template struct {
-	Name        string     `yaml:"name"`
-	Description string     `yaml:"description"`
-	Questions   []Question `yaml:"questions"`
-}
-
-func LoadTemplates() ([]Template, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return nil, err
-	}
-
-	templatesDir := filepath.Join(configDir, "journal-cli", "templates")
-	if err := fs.EnsureDir(templatesDir); err != nil {
-		return nil, err
-	}
-
-	files, err := os.ReadDir(templatesDir)
-	if err != nil {
-		return nil, err
+// Key identifies the question's answer in an entry: its ID, or the title
+// for templates written before questions had IDs.
+func (q Question) Key() string {
+	if q.ID != "" {
+		return q.ID
 	}
+	return q.Title
+}
 
-	var templates []Template
+type Template struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Period      domain.Period `yaml:"period"`  // Entries the template is for; empty for daily entries
+	Extends     string        `yaml:"extends"` // Template whose questions come first; see compose.go
+	Include     []string      `yaml:"include"` // Blocks from BlocksDir added after the inherited questions
+	Questions   []Question    `yaml:"questions"`
+}
 
-	// If no templates found, create defaults from embedded files
-	if len(files) == 0 {
-		entries, err := defaultTemplatesFS.ReadDir("defaults")
-		if err != nil {
-			return nil, err
+// Check reports the first problem with the template's questions.
+func (t Template) Check() error {
+	for i, q := range t.Questions {
+		if err := q.Check(); err != nil {
+			return err
 		}
+		if err := t.checkCondition(i); err != nil {
+			return err
+		}
+		if j := t.firstWithKey(q.Key()); j < i {
+			return fmt.Errorf("question %q: id %q is already used by %q", q.Title, q.Key(), t.Questions[j].Title)
+		}
+	}
+	return nil
+}
 
-		for _, entry := range entries {
-			data, err := defaultTemplatesFS.ReadFile("defaults/" + entry.Name())
-			if err != nil {
-				continue
-			}
-
-			// Write to config dir
-			if err := fs.WriteFile(filepath.Join(templatesDir, entry.Name()), data); err != nil {
-				continue
-			}
-
-			var t Template
-			if err := yaml.Unmarshal(data, &t); err != nil {
-				continue
-			}
-			templates = append(templates, t)
+// firstWithKey returns the index of the first question with the given key.
+func (t Template) firstWithKey(key string) int {
+	for i, q := range t.Questions {
+		if q.Key() == key {
+			return i
 		}
-		return templates, nil
 	}
+	return -1
+}
 
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".yaml" || filepath.Ext(file.Name()) == ".yml" {
-			data, err := os.ReadFile(filepath.Join(templatesDir, file.Name()))
-			if err != nil {
-				continue
-			}
-			var t Template
-			if err := yaml.Unmarshal(data, &t); err != nil {
-				continue
-			}
-			templates = append(templates, t)
+// ForPeriod returns the templates for entries of period p.
+func ForPeriod(templates []Template, p domain.Period) []Template {
+	var out []Template
+	for _, t := range templates {
+		if t.Period == p {
+			out = append(out, t)
 		}
 	}
+	return out
+}
 
-	return templates, nil
+// Defaults returns the bundled templates, as seeded into an empty
+// templates directory.
+func Defaults() ([]Template, error) {
+	mem := fs.NewMemFS()
+	templates, _, err := Load(mem, "defaults")
+	return templates, err
 }
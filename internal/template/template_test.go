@@ -3,33 +3,54 @@ package template
 import (
     "os"
     "path/filepath"
+    "strings"
     "testing"
+
+    "journal-cli/internal/fs"
 )
 
-func TestLoadTemplatesDefaults(t *testing.T) {
-    tmp := t.TempDir()
-    prev := os.Getenv("XDG_CONFIG_HOME")
-    os.Setenv("XDG_CONFIG_HOME", tmp)
-    defer os.Setenv("XDG_CONFIG_HOME", prev)
+func TestLoadDefaults(t *testing.T) {
+    templatesDir := filepath.Join(t.TempDir(), "templates")
 
-    _, err := LoadTemplates()
+    templates, diags, err := Load(fs.OS{}, templatesDir)
     if err != nil {
-        t.Fatalf("LoadTemplates error: %v", err)
+        t.Fatalf("Load error: %v", err)
+    }
+    if len(templates) == 0 || len(diags) != 0 {
+        t.Fatalf("seeded defaults: %d templates, diagnostics %v", len(templates), diags)
+    }
+    if _, err := os.Stat(filepath.Join(templatesDir, BlocksDir, "check-in.yaml")); err != nil {
+        t.Fatalf("check-in block not seeded: %v", err)
     }
 
-    // It's acceptable (depending on environment) for templates to be empty.
+    // The daily templates share the check-in block; workday-balance
+    // rewords its mood question in place.
+    want := map[string][]string{
+        "daily-human-dev": {"mood", "energy", "stress"},
+        "workday-balance": {"mood", "energy", "time"},
+    }
+    for _, tmpl := range templates {
+        ids, ok := want[tmpl.Name]
+        if !ok {
+            continue
+        }
+        for i, id := range ids {
+            if tmpl.Questions[i].ID != id {
+                t.Errorf("%s question %d = %q, want %q", tmpl.Name, i, tmpl.Questions[i].ID, id)
+            }
+        }
+        if tmpl.Name == "workday-balance" && !strings.Contains(tmpl.Questions[0].Title, "dominant mood") {
+            t.Errorf("workday-balance mood = %q", tmpl.Questions[0].Title)
+        }
+        delete(want, tmpl.Name)
+    }
+    if len(want) > 0 {
+        t.Errorf("templates missing: %v", want)
+    }
 }
 
-func TestLoadTemplatesWithMalformedFile(t *testing.T) {
-    tmp := t.TempDir()
-    prev := os.Getenv("XDG_CONFIG_HOME")
-    os.Setenv("XDG_CONFIG_HOME", tmp)
-    defer os.Setenv("XDG_CONFIG_HOME", prev)
-
-    templatesDir := filepath.Join(tmp, "journal-cli", "templates")
-    if err := os.MkdirAll(templatesDir, 0755); err != nil {
-        t.Fatalf("mkdir failed: %v", err)
-    }
+func TestLoadWithMalformedFile(t *testing.T) {
+    templatesDir := t.TempDir()
 
     // Write a malformed template file
     bad := "not: : valid: yaml: :::"
@@ -37,10 +58,252 @@ func TestLoadTemplatesWithMalformedFile(t *testing.T) {
         t.Fatalf("write failed: %v", err)
     }
 
-    _, err := LoadTemplates()
+    // The loader succeeds and reports the file instead of failing.
+    templates, diags, err := Load(fs.OS{}, templatesDir)
+    if err != nil {
+        t.Fatalf("Load error: %v", err)
+    }
+    if len(templates) != 0 || len(diags) != 1 || !diags[0].Fatal || !strings.Contains(diags[0].String(), "bad.yaml") {
+        t.Fatalf("templates %v, diagnostics %v", templates, diags)
+    }
+}
+
+func TestQuestionTypes(t *testing.T) {
+    one, five := 1.0, 5.0
+    scale := Question{Title: "Focus", Type: TypeScale, Min: &one, Max: &five}
+    number := Question{Title: "Sleep", Type: TypeNumber, Unit: "h", Min: new(float64)}
+    choice := Question{Title: "Weather", Type: TypeChoice, Options: []string{"sunny", "rainy"}}
+    multi := Question{Title: "Did", Type: TypeMultiChoice, Options: []string{"code", "walk"}}
+    boolean := Question{Title: "Exercised", Type: TypeBoolean}
+
+    cases := []struct {
+        q     Question
+        text  string
+        valid bool
+    }{
+        {scale, "", true},
+        {scale, "4/5", true},
+        {scale, "6", false},
+        {scale, "x", false},
+        {number, "7.5 h", true},
+        {number, "7.5", true},
+        {number, "-1", false},
+        {number, "lots", false},
+        {choice, "rainy", true},
+        {choice, "snowy", false},
+        {multi, "- code\n- walk", true},
+        {multi, "- swim", false},
+        {boolean, "Yes", true},
+        {boolean, "maybe", false},
+        {Question{Title: "Free"}, "anything", true},
+    }
+    for _, c := range cases {
+        if err := c.q.Validate(c.text); (err == nil) != c.valid {
+            t.Errorf("%s.Validate(%q) = %v, want valid=%v", c.q.Title, c.text, err, c.valid)
+        }
+    }
+
+    if v, ok := number.Metric("7.5 h"); !ok || v != 7.5 {
+        t.Errorf("number metric = %v, %v", v, ok)
+    }
+    if v, ok := boolean.Metric("No"); !ok || v != 0 {
+        t.Errorf("boolean metric = %v, %v", v, ok)
+    }
+    if _, ok := choice.Metric("rainy"); ok {
+        t.Errorf("choice answers should not be metrics")
+    }
+    if got := scale.FormatScale(4); got != "4/5" {
+        t.Errorf("FormatScale = %q", got)
+    }
+    if got := ParseItems(FormatItems([]string{"a", " ", "b"})); len(got) != 2 || got[1] != "b" {
+        t.Errorf("items round trip = %v", got)
+    }
+}
+
+func TestQuestionCheck(t *testing.T) {
+    bad := []Question{
+        {Title: "a", Type: "slider"},
+        {Title: "b", Type: TypeChoice},
+        {Title: "c", Type: TypeScale, Labels: map[int]string{11: "too high"}},
+    }
+    for _, q := range bad {
+        if q.Check() == nil {
+            t.Errorf("expected %q to be rejected", q.Title)
+        }
+    }
+    if err := (Question{Title: "d", Type: TypeScale}).Check(); err != nil {
+        t.Errorf("default scale rejected: %v", err)
+    }
+}
+
+func TestConditions(t *testing.T) {
+    cases := []struct {
+        expr   string
+        answer string
+        holds  bool
+    }{
+        {"energy <= 3", "2/5", true},
+        {"energy <= 3", "4/5", false},
+        {"energy ≤ 3", "3", true},
+        {"energy <= 3", "", false},
+        {"sleep > 7", "7.5 h", true},
+        {"weather == rainy", "Rainy", true},
+        {"weather != rainy", "sunny", true},
+        {"weather = 'sunny'", "sunny", true},
+        {"did contains walk", "- code\n- walk", true},
+        {"exercised", "Yes", true},
+        {"exercised", "No", false},
+        {"exercised", "", false},
+    }
+    for _, c := range cases {
+        cond, err := ParseCondition(c.expr)
+        if err != nil {
+            t.Fatalf("ParseCondition(%q): %v", c.expr, err)
+        }
+        if got := cond.Holds(c.answer); got != c.holds {
+            t.Errorf("%q with answer %q = %v, want %v", c.expr, c.answer, got, c.holds)
+        }
+    }
+
+    for _, bad := range []string{"", "energy <=", "energy is low", "<= 3"} {
+        if _, err := ParseCondition(bad); err == nil {
+            t.Errorf("ParseCondition(%q) should fail", bad)
+        }
+    }
+}
+
+func TestTemplateCheckConditions(t *testing.T) {
+    ok := Template{Questions: []Question{
+        {ID: "energy", Title: "Energy", Type: TypeScale},
+        {ID: "drained", Title: "Drained", ShowIf: "energy <= 3"},
+        {ID: "why", Title: "Why", ShowIf: "mood == sad"},
+    }}
+    if err := ok.Check(); err != nil {
+        t.Errorf("valid template rejected: %v", err)
+    }
+
+    forward := Template{Questions: []Question{
+        {ID: "drained", Title: "Drained", ShowIf: "energy <= 3"},
+        {ID: "energy", Title: "Energy", Type: TypeScale},
+    }}
+    if err := forward.Check(); err == nil {
+        t.Errorf("show_if referring to a later question should be rejected")
+    }
+
+    badDefault := Template{Questions: []Question{{Title: "Energy", Type: TypeScale, Default: "11"}}}
+    if err := badDefault.Check(); err == nil {
+        t.Errorf("out of range default should be rejected")
+    }
+}
+
+func TestLoadReportsDiagnostics(t *testing.T) {
+    mem := fs.NewMemFS()
+    files := map[string]string{
+        "a.yaml": "name: daily\nquestions:\n  - id: mood\n    title: Mood\n    requird: true\n  - title: Notes\n",
+        "b.yaml": "name: daily\nquestions:\n  - id: x\n    title: X\n",
+        "c.yaml": "name: c\nquestions:\n  - id: a\n    title: A\n  - id: a\n    title: B\n",
+        "d.yaml": "name: d\nquestions: [\n",
+        "e.yaml": "name: e\nquestions:\n  - id: n\n    title: N\n    type: number\n    min: abc\n",
+        "f.yaml": "name: f\nperiod: fortnight\nquestions:\n  - id: x\n    title: X\n",
+    }
+    for name, content := range files {
+        if err := mem.WriteFile(filepath.Join("/t", name), []byte(content)); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    templates, diags, err := Load(mem, "/t")
     if err != nil {
-        t.Fatalf("LoadTemplates error: %v", err)
+        t.Fatalf("Load error: %v", err)
+    }
+    if len(templates) != 1 || templates[0].Name != "daily" || len(templates[0].Questions) != 2 {
+        t.Fatalf("expected only a.yaml to load, got %+v", templates)
     }
 
-    // The loader should succeed even if templates are malformed; no further guarantees.
+    var got []string
+    for _, d := range diags {
+        got = append(got, d.Short())
+    }
+    all := strings.Join(got, "\n")
+    for _, want := range []string{
+        `a.yaml:5:5: warning: unknown key "requird"`,
+        `a.yaml:6:5: warning: question "Notes" has no id`,
+        `b.yaml:1:7: error: template name "daily" is already used by a.yaml`,
+        `c.yaml:5:9: error: question "B": id "a" is already used by "A"`,
+        `d.yaml:2: error: did not find expected node content`,
+        `e.yaml:6: error: cannot unmarshal`,
+        `f.yaml:2:9: error: unknown period "fortnight"`,
+    } {
+        if !strings.Contains(all, want) {
+            t.Errorf("missing diagnostic %q in:\n%s", want, all)
+        }
+    }
+}
+
+func TestLoadResolvesExtendsAndInclude(t *testing.T) {
+    mem := fs.NewMemFS()
+    files := map[string]string{
+        "base.yaml":            "name: base\ndescription: Base\nquestions:\n  - id: mood\n    title: Mood\n  - id: notes\n    title: Notes\n",
+        "variant.yaml":         "name: variant\nextends: base\ninclude: [check-in]\nquestions:\n  - id: mood\n    title: Mood today\n    type: scale\n  - id: win\n    title: Win\n    show_if: energy >= 5\n",
+        "blocks/check-in.yaml": "include: [sleep]\nquestions:\n  - id: energy\n    title: Energy\n    type: scale\n",
+        "blocks/sleep.yaml":    "questions:\n  - id: sleep\n    title: Sleep\n    type: number\n    unit: h\n",
+        "loop-a.yaml":          "name: loop-a\nextends: loop-b\nquestions:\n  - id: a\n    title: A\n",
+        "loop-b.yaml":          "name: loop-b\nextends: loop-a\nquestions:\n  - id: b\n    title: B\n",
+        "missing.yaml":         "name: missing\nextends: nope\ninclude: [absent]\nquestions:\n  - id: x\n    title: X\n",
+        "blocks/self.yaml":     "include: [self]\nquestions:\n  - id: s\n    title: S\n",
+        "selfish.yaml":         "name: selfish\ninclude:\n  - self\n",
+    }
+    for name, content := range files {
+        if err := mem.WriteFile(filepath.Join("/t", name), []byte(content)); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    templates, diags, err := Load(mem, "/t")
+    if err != nil {
+        t.Fatalf("Load error: %v", err)
+    }
+    byName := map[string]Template{}
+    for _, tmpl := range templates {
+        byName[tmpl.Name] = tmpl
+    }
+    if len(byName) != 2 {
+        t.Errorf("expected only base and variant to load, got %d templates", len(byName))
+    }
+
+    variant, ok := byName["variant"]
+    if !ok {
+        t.Fatalf("variant did not load: %v", diags)
+    }
+    var keys []string
+    for _, q := range variant.Questions {
+        keys = append(keys, q.Key())
+    }
+    if got := strings.Join(keys, ","); got != "mood,notes,sleep,energy,win" {
+        t.Errorf("variant questions = %s", got)
+    }
+    if q := variant.Questions[0]; q.Title != "Mood today" || q.Kind() != TypeScale {
+        t.Errorf("variant should override the inherited mood question in place, got %+v", q)
+    }
+    if variant.Description != "Base" {
+        t.Errorf("variant should inherit the description, got %q", variant.Description)
+    }
+
+    var got []string
+    for _, d := range diags {
+        got = append(got, d.Short())
+    }
+    all := strings.Join(got, "\n")
+    for _, want := range []string{
+        `loop-b.yaml:2:10: error: extended template "loop-a" forms a cycle: loop-a -> loop-b -> loop-a`,
+        `loop-a.yaml:2:10: error: extended template "loop-b" has errors`,
+        `missing.yaml:2:10: error: extended template "nope" not found`,
+        `missing.yaml:3:11: error: included block "absent" not found`,
+        `blocks/self.yaml:1:11: error: included block "self" forms a cycle: blocks/self -> blocks/self`,
+        `selfish.yaml:3:5: error: included block "self" has errors`,
+    } {
+        if !strings.Contains(all, want) {
+            t.Errorf("missing diagnostic %q in:\n%s", want, all)
+        }
+    }
 }
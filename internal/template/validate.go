@@ -0,0 +1,303 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"journal-cli/internal/domain"
+	"journal-cli/internal/fs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem found in a template file.
+type Diagnostic struct {
+	File    string
+	Line    int // 1-based; 0 when unknown
+	Column  int // 1-based; 0 when unknown
+	Message string
+	// Fatal problems keep the template from being loaded; the others are
+	// warnings.
+	Fatal bool
+}
+
+func (d Diagnostic) String() string {
+	return d.format(d.File)
+}
+
+// Short is like String but names the file relative to the templates
+// directory.
+func (d Diagnostic) Short() string {
+	file := filepath.Base(d.File)
+	if filepath.Base(filepath.Dir(d.File)) == BlocksDir {
+		file = filepath.Join(BlocksDir, file)
+	}
+	return d.format(file)
+}
+
+func (d Diagnostic) format(file string) string {
+	var pos strings.Builder
+	pos.WriteString(file)
+	if d.Line > 0 {
+		fmt.Fprintf(&pos, ":%d", d.Line)
+		if d.Column > 0 {
+			fmt.Fprintf(&pos, ":%d", d.Column)
+		}
+	}
+	level := "warning"
+	if d.Fatal {
+		level = "error"
+	}
+	return fmt.Sprintf("%s: %s: %s", pos.String(), level, d.Message)
+}
+
+// Load loads every template in templatesDir, seeding it with the embedded
+// defaults when the directory is empty, and returns the problems found in
+// the files. Templates with fatal problems are left out of the result.
+func Load(fsys fs.FS, templatesDir string) ([]Template, []Diagnostic, error) {
+	if err := fsys.MkdirAll(templatesDir); err != nil {
+		return nil, nil, err
+	}
+	files, err := fsys.ReadDir(templatesDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// If no templates found, create defaults from embedded files
+	if len(files) == 0 {
+		if err := seedDefaults(fsys, templatesDir); err != nil {
+			return nil, nil, err
+		}
+		if files, err = fsys.ReadDir(templatesDir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var paths []string
+	for _, file := range files {
+		if !file.IsDir() && isTemplateFile(file.Name()) {
+			paths = append(paths, filepath.Join(templatesDir, file.Name()))
+		}
+	}
+	templates, diags := Validate(fsys, paths)
+	return templates, diags, nil
+}
+
+// Validate checks the template files at paths and returns the templates
+// that can be used along with every problem found. Templates they extend
+// and blocks they include are looked up next to them when they are not
+// among paths.
+func Validate(fsys fs.FS, paths []string) ([]Template, []Diagnostic) {
+	r := newResolver(fsys)
+	var sources []*source
+	for _, path := range paths {
+		s := r.parse(path, false)
+		r.report(s)
+		if !s.ok {
+			continue
+		}
+		// The first file that loads keeps the name.
+		if other, dup := r.names[s.t.Name]; dup {
+			r.add(path, mappingValue(s.root, "name"), true, "template name %q is already used by %s", s.t.Name, filepath.Base(other.path))
+			continue
+		}
+		r.names[s.t.Name] = s
+		sources = append(sources, s)
+	}
+
+	var templates []Template
+	for _, s := range sources {
+		if t, ok := r.template(s); ok {
+			templates = append(templates, t)
+		}
+	}
+	return templates, r.diags
+}
+
+func hasFatal(diags []Diagnostic) bool {
+	return slices.ContainsFunc(diags, func(d Diagnostic) bool { return d.Fatal })
+}
+
+func isTemplateFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// seedDefaults writes the embedded templates, and the blocks they
+// include, to templatesDir.
+func seedDefaults(fsys fs.FS, templatesDir string) error {
+	return iofs.WalkDir(defaultTemplatesFS, "defaults", func(path string, entry iofs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := defaultTemplatesFS.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		// Write to config dir
+		rel := strings.TrimPrefix(path, "defaults/")
+		_ = fsys.WriteFile(filepath.Join(templatesDir, filepath.FromSlash(rel)), data)
+		return nil
+	})
+}
+
+var lineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// checkFile parses one template or block file. It returns nil when the
+// file cannot be decoded at all; otherwise the template, its YAML tree for
+// positions and the problems found in the file itself. Problems that depend
+// on other files, such as show_if conditions referring to an inherited
+// question, are found when the template is resolved.
+func checkFile(path string, data []byte, block bool) (*Template, *yaml.Node, []Diagnostic) {
+	var diags []Diagnostic
+	add := func(n *yaml.Node, fatal bool, format string, args ...any) {
+		line, col := position(n)
+		diags = append(diags, Diagnostic{File: path, Line: line, Column: col, Message: fmt.Sprintf(format, args...), Fatal: fatal})
+	}
+	addErr := func(msg string) {
+		d := Diagnostic{File: path, Message: strings.TrimPrefix(msg, "yaml: "), Fatal: true}
+		if m := lineRe.FindStringSubmatch(msg); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Message = m[2]
+		}
+		diags = append(diags, d)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		addErr(err.Error())
+		return nil, nil, diags
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		add(nil, true, "file is empty")
+		return nil, nil, diags
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		if block {
+			add(root, true, "expected a mapping with questions")
+		} else {
+			add(root, true, "expected a mapping with name, description and questions")
+		}
+		return nil, nil, diags
+	}
+
+	// Unknown keys are reported but do not stop the template from loading,
+	// so a typo in an optional setting is visible without losing the
+	// template.
+	if block {
+		checkKeys(root, reflect.TypeFor[Block](), add)
+	} else {
+		checkKeys(root, reflect.TypeFor[Template](), add)
+	}
+	questions := mappingValue(root, "questions")
+	if questions != nil && questions.Kind == yaml.SequenceNode {
+		for _, qn := range questions.Content {
+			if qn.Kind == yaml.MappingNode {
+				checkKeys(qn, reflect.TypeFor[Question](), add)
+			}
+		}
+	}
+
+	var t Template
+	var err error
+	if block {
+		var b Block
+		err = yaml.Unmarshal(data, &b)
+		t = Template{Description: b.Description, Include: b.Include, Questions: b.Questions}
+	} else {
+		err = yaml.Unmarshal(data, &t)
+	}
+	if err != nil {
+		var te *yaml.TypeError
+		if errors.As(err, &te) {
+			for _, msg := range te.Errors {
+				addErr(msg)
+			}
+		} else {
+			addErr(err.Error())
+		}
+		return nil, root, diags
+	}
+
+	switch {
+	case block && len(t.Questions) == 0 && len(t.Include) == 0:
+		add(root, false, "block has no questions")
+	case !block && strings.TrimSpace(t.Name) == "":
+		add(root, true, "missing template name")
+	}
+	if p, err := domain.ParsePeriod(string(t.Period)); err != nil {
+		add(valueOr(root, "period"), true, "%v", err)
+	} else {
+		t.Period = p
+	}
+	for i := range t.Questions {
+		q := &t.Questions[i]
+		qn := questions.Content[i]
+		q.origin = origin{file: path, node: qn}
+		if err := q.Check(); err != nil {
+			add(qn, true, "%v", err)
+		}
+		switch {
+		case strings.TrimSpace(q.Title) == "":
+			add(qn, true, "question %d has no title", i+1)
+		case q.ID == "":
+			add(qn, false, "question %q has no id; its answers are matched by title", q.Title)
+		}
+		if j := t.firstWithKey(q.Key()); j < i {
+			add(valueOr(qn, "id"), true, "question %q: id %q is already used by %q", q.Title, q.Key(), t.Questions[j].Title)
+		}
+	}
+	return &t, root, diags
+}
+
+// checkKeys reports the keys of mapping m that are not yaml fields of typ.
+func checkKeys(m *yaml.Node, typ reflect.Type, add func(*yaml.Node, bool, string, ...any)) {
+	var known []string
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			known = append(known, name)
+		}
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if key := m.Content[i]; !slices.Contains(known, key.Value) {
+			add(key, false, "unknown key %q (expected one of %s)", key.Value, strings.Join(known, ", "))
+		}
+	}
+}
+
+// mappingValue returns the value node of key in mapping m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// valueOr returns the value node of key in m, or m itself.
+func valueOr(m *yaml.Node, key string) *yaml.Node {
+	if v := mappingValue(m, key); v != nil {
+		return v
+	}
+	return m
+}
+
+func position(n *yaml.Node) (line, col int) {
+	if n == nil {
+		return 0, 0
+	}
+	return n.Line, n.Column
+}
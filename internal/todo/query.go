@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+	"journal-cli/internal/index"
+	"journal-cli/internal/layout"
+)
+
+// Item is a todo as last seen in the journal.
+type Item struct {
+	domain.Todo
+	Date    time.Time // Date of the entry it was last seen in
+	Path    string    // File of that entry
+	Backlog bool      // Last seen in the entry's Backlog section
+}
+
+// Collect goes through the daily entries of x that l names and that fall
+// within span (the zero Range for all) and returns every todo once, in the
+// state of the latest entry listing it, in the order they were first seen.
+// Entries that could not be parsed are skipped and their paths returned.
+func Collect(x *index.Index, l layout.Layout, span dateexpr.Range) (items []Item, unreadable []string) {
+	byID, byText := map[string]int{}, map[string]int{}
+	for _, e := range x.Daily(l) {
+		if !span.Contains(e.Date) {
+			continue
+		}
+		entry := e.Entry
+		if entry == nil {
+			unreadable = append(unreadable, e.Path)
+			continue
+		}
+		for _, section := range []struct {
+			todos   []domain.Todo
+			backlog bool
+		}{{entry.Todos, false}, {entry.Backlog, true}} {
+			for _, t := range section.todos {
+				i, seen := byID[t.ID]
+				if t.ID == "" || !seen {
+					// Todos written before IDs were recorded match by text.
+					if j, ok := byText[t.Text]; ok && (t.ID == "" || items[j].ID == "") {
+						i, seen = j, true
+					}
+				}
+				if t.Created.IsZero() {
+					// Undated todos were created by the first entry listing them.
+					t.Created = e.Date
+					if seen {
+						t.Created = items[i].Created
+					}
+				}
+				item := Item{Todo: t, Date: e.Date, Path: e.Path, Backlog: section.backlog}
+				if seen {
+					if items[i].Date.Equal(e.Date) && !items[i].Backlog {
+						continue // Listed in both sections; Todos wins
+					}
+					items[i] = item
+				} else {
+					i = len(items)
+					items = append(items, item)
+				}
+				if t.ID != "" {
+					byID[t.ID] = i
+				}
+				byText[t.Text] = i
+			}
+		}
+	}
+	return items, unreadable
+}
+
+// Filter selects todos returned by Collect. The zero Filter selects all.
+type Filter struct {
+	Statuses []domain.Status // Any of these; empty for any status
+	Overdue  bool            // Open and due before Today
+	Stale    int             // Open, carried over and at least this many days old on Today; 0 for any
+	Tag      string          // Tagged with this tag or one nested in it, without "#"
+	Text     string          // Text contains this, ignoring case
+	Today    time.Time
+}
+
+// Match reports whether the filter selects it.
+func (f Filter) Match(it Item) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, it.Status) {
+		return false
+	}
+	if f.Overdue && !it.Overdue(f.Today) {
+		return false
+	}
+	if f.Stale > 0 && !it.Stale(f.Today, f.Stale) {
+		return false
+	}
+	if f.Tag != "" {
+		want := strings.ToLower(strings.TrimPrefix(f.Tag, "#"))
+		if !slices.ContainsFunc(it.Tags(), func(tag string) bool {
+			tag = strings.ToLower(tag)
+			return tag == want || strings.HasPrefix(tag, want+"/")
+		}) {
+			return false
+		}
+	}
+	return f.Text == "" || strings.Contains(strings.ToLower(it.Text), strings.ToLower(f.Text))
+}
@@ -1,21 +1,24 @@
 package todo
 
 import (
-	"os"
+	"slices"
 	"time"
 
 	"journal-cli/internal/domain"
 	"journal-cli/internal/fs"
+	"journal-cli/internal/layout"
 	"journal-cli/internal/markdown"
 )
 
-// GetBacklog reads the journal entry from the given path and returns unchecked todos.
-func GetBacklog(path string) ([]domain.Todo, error) {
-	if !fs.Exists(path) {
+// GetBacklog reads the journal entry from the given path and returns its
+// todos that are not done or cancelled, each item once. Todos written
+// before creation dates were recorded are dated to the entry.
+func GetBacklog(fsys fs.FS, path string) ([]domain.Todo, error) {
+	if !fsys.Exists(path) {
 		return []domain.Todo{}, nil
 	}
 
-	content, err := fs.ReadFile(path)
+	content, err := fsys.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -27,32 +30,40 @@ func GetBacklog(path string) ([]domain.Todo, error) {
 
 	var backlog []domain.Todo
 
-	// Collect unchecked todos from "Todos" section
-	for _, todo := range entry.Todos {
-		if !todo.Done {
-			backlog = append(backlog, todo)
+	// Collect unchecked todos from the "Todos" section, then from the
+	// "Backlog" section (recursive backlog)
+	for _, todo := range slices.Concat(entry.Todos, entry.Backlog) {
+		if todo.Closed() || slices.ContainsFunc(backlog, todo.Same) {
+			continue
 		}
-	}
-
-	// Collect unchecked todos from "Backlog" section (recursive backlog)
-	for _, todo := range entry.Backlog {
-		if !todo.Done {
-			backlog = append(backlog, todo)
+		if todo.Created.IsZero() {
+			todo.Created = entry.Date
 		}
+		backlog = append(backlog, todo)
 	}
 
 	return backlog, nil
 }
 
-// GetPreviousJournalPath calculates the path for the previous day's journal.
-// This is a helper, but the actual path construction depends on config.
-// So maybe we just pass the date and let the caller construct the path?
-// Or we pass the base dir.
-func GetPreviousJournalPath(baseDir string, date time.Time) string {
-	prevDate := date.AddDate(0, 0, -1)
-	filename := prevDate.Format("2006-01-02") + ".md"
-	// Assuming flat structure as per prompt: <ObsidianVault>/Journal/Daily/YYYY-MM-DD.md
-	// But the prompt says "Stored in <ObsidianVault>/Journal/Daily/"
-	// So we just join baseDir with filename.
-	return baseDir + string(os.PathSeparator) + filename
+// CarryOver returns the todos as carried over to the entry for date.
+func CarryOver(todos []domain.Todo, date time.Time) []domain.Todo {
+	out := make([]domain.Todo, len(todos))
+	for i, t := range todos {
+		out[i] = t.Carry(date)
+	}
+	return out
+}
+
+// FindPreviousJournal returns the path and date of the most recent entry
+// before date, as l places entries in baseDir, looking back at most
+// maxDays days. ok is false when there is none in that window, so a
+// weekend or a holiday does not lose the open todos.
+func FindPreviousJournal(fsys fs.FS, baseDir string, l layout.Layout, date time.Time, maxDays int) (path string, prev time.Time, ok bool) {
+	for i := 1; i <= maxDays; i++ {
+		prev = date.AddDate(0, 0, -i)
+		if path = l.Path(baseDir, prev); fsys.Exists(path) {
+			return path, prev, true
+		}
+	}
+	return "", time.Time{}, false
 }
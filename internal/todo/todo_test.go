@@ -1,12 +1,17 @@
 package todo
 
 import (
+    "fmt"
     "path/filepath"
-    "strings"
+    "slices"
     "testing"
     "time"
 
+    "journal-cli/internal/dateexpr"
+    "journal-cli/internal/domain"
     "journal-cli/internal/fs"
+    "journal-cli/internal/index"
+    "journal-cli/internal/layout"
 )
 
 func TestGetBacklog(t *testing.T) {
@@ -20,6 +25,9 @@ template: daily-human-dev
 ## ✅ Todos – Today
 - [ ] Unchecked task
 - [x] Checked task
+- [/] Started task
+- [-] Cancelled task
+- [>] Deferred task
 
 ## 🔁 Backlog
 - [ ] Backlogged task
@@ -35,13 +43,13 @@ template: daily-human-dev
         t.Fatalf("expected file to exist: %s", path)
     }
 
-    items, err := GetBacklog(path)
+    items, err := GetBacklog(fs.OS{}, path)
     if err != nil {
         t.Fatalf("GetBacklog returned error: %v", err)
     }
 
-    if len(items) != 2 {
-        t.Fatalf("expected 2 backlog items (1 todo + 1 backlog), got %d", len(items))
+    if len(items) != 4 {
+        t.Fatalf("expected 4 backlog items (3 todos + 1 backlog), got %d", len(items))
     }
 
     // Basic content checks
@@ -49,19 +57,35 @@ template: daily-human-dev
     for _, it := range items {
         found[it.Text] = true
     }
-    if !found["Unchecked task"] || !found["Backlogged task"] {
+    if !found["Unchecked task"] || !found["Started task"] || !found["Deferred task"] || !found["Backlogged task"] {
         t.Fatalf("unexpected backlog items: %v", found)
     }
 }
 
-func TestGetPreviousJournalPath(t *testing.T) {
+func TestFindPreviousJournal(t *testing.T) {
+    mem := fs.NewMemFS()
     base := "/tmp/journal"
-    // Use a known date
-    // Previous path should end with 2025-12-29.md
-    // We don't assert separator specifics, just suffix
-    got := GetPreviousJournalPath(base, fsTime())
-    if !strings.HasSuffix(got, "2025-12-29.md") {
-        t.Fatalf("unexpected previous path: %s", got)
+    nested, err := layout.Parse("YYYY/MM/YYYY-MM-DD")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{"2025/12/2025-12-26.md", "2025/12/2025-12-30.md"} {
+        if err := mem.WriteFile(filepath.Join(base, filepath.FromSlash(name)), []byte("x")); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // Over the weekend: the Friday entry, not the missing Monday one.
+    got, date, ok := FindPreviousJournal(mem, base, nested, fsTime(), 30)
+    if !ok || got != filepath.Join(base, "2025", "12", "2025-12-26.md") || date.Format("2006-01-02") != "2025-12-26" {
+        t.Fatalf("FindPreviousJournal = %s, %v, %v", got, date, ok)
+    }
+
+    if _, _, ok := FindPreviousJournal(mem, base, nested, fsTime(), 3); ok {
+        t.Error("expected nothing within 3 days")
+    }
+    if _, _, ok := FindPreviousJournal(mem, base, layout.Layout{}, fsTime(), 30); ok {
+        t.Error("expected nothing in the flat layout")
     }
 }
 
@@ -69,3 +93,72 @@ func TestGetPreviousJournalPath(t *testing.T) {
 func fsTime() time.Time {
     return time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
 }
+
+func TestCollect(t *testing.T) {
+    mem := fs.NewMemFS()
+    files := map[string]string{
+        "2025-12-01.md": "---\ndate: 2025-12-01\n---\n## ✅ Todos – Today\n" +
+            "- [ ] Write report #work 🆔 aaaaaa ➕ 2025-12-01 📅 2025-12-03\n- [ ] Call mum\n- [x] Buy milk\n",
+        "2025-12-02.md": "---\ndate: 2025-12-02\n---\n## ✅ Todos – Today\n" +
+            "- [x] Call mum\n- [-] Plan trip #project/travel\n\n" +
+            "## 🔁 Backlog\n- [ ] Write report #work [carried:: 1] [carried_on:: 2025-12-02] 🆔 aaaaaa ➕ 2025-12-01 📅 2025-12-03\n",
+        "2025-12-05.md": "not a journal entry\n---\n",
+    }
+    for name, content := range files {
+        if err := mem.WriteFile(filepath.Join("j", name), []byte(content)); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    x := index.New("j")
+    if err := x.Update(mem); err != nil {
+        t.Fatal(err)
+    }
+    items, unreadable := Collect(x, layout.Layout{}, dateexpr.Range{})
+    if !slices.Equal(unreadable, []string{filepath.Join("j", "2025-12-05.md")}) {
+        t.Errorf("Collect: unreadable %v", unreadable)
+    }
+    var got []string
+    for _, it := range items {
+        got = append(got, fmt.Sprintf("%s %s %s", it.Text, it.Status, it.Date.Format("01-02")))
+    }
+    want := []string{"Write report #work open 12-02", "Call mum done 12-02", "Buy milk done 12-01", "Plan trip #project/travel cancelled 12-02"}
+    if !slices.Equal(got, want) {
+        t.Fatalf("Collect = %q, want %q", got, want)
+    }
+    if !items[0].Backlog || items[0].Carried != 1 {
+        t.Errorf("latest state not kept: %+v", items[0])
+    }
+
+    today := time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)
+    for _, tt := range []struct {
+        filter Filter
+        want   int
+    }{
+        {Filter{}, 4},
+        {Filter{Statuses: []domain.Status{domain.StatusDone}}, 2},
+        {Filter{Overdue: true, Today: today}, 1},
+        {Filter{Stale: 7, Today: today}, 1},
+        {Filter{Stale: 10, Today: today}, 0},
+        {Filter{Tag: "project"}, 1},
+        {Filter{Tag: "#Work"}, 1},
+        {Filter{Text: "MUM"}, 1},
+    } {
+        n := 0
+        for _, it := range items {
+            if tt.filter.Match(it) {
+                n++
+            }
+        }
+        if n != tt.want {
+            t.Errorf("%+v matched %d, want %d", tt.filter, n, tt.want)
+        }
+    }
+
+    // A range limits the entries read.
+    span := dateexpr.Range{Start: time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local), End: time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local)}
+    items, _ = Collect(x, layout.Layout{}, span)
+    if len(items) != 3 || items[1].Status != domain.StatusOpen {
+        t.Errorf("Collect(2025-12-01) = %+v", items)
+    }
+}
@@ -0,0 +1,354 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"journal-cli/internal/domain"
+	"journal-cli/internal/index"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// BrowseItem is an entry listed in the browser.
+type BrowseItem struct {
+	Date  time.Time
+	Path  string
+	Entry *domain.JournalEntry
+	words []string // Distinct words of the entry, for the search
+}
+
+// BrowseAction is what the user left the browser to do.
+type BrowseAction int
+
+const (
+	BrowseQuit  BrowseAction = iota
+	BrowseEdit               // Open the selected entry in the journaling TUI
+	BrowseTodos              // Update the todos of the selected entry
+)
+
+// Browser is the browse mode: past entries listed by month on the left,
+// the selected one on the right, and a search that narrows the list as it
+// is typed.
+type Browser struct {
+	Items []BrowseItem // Newest first
+
+	Cursor      int // Index in the shown items
+	SearchInput textinput.Model
+	Searching   bool // Typing in SearchInput
+
+	Action BrowseAction
+	Done   bool
+
+	shown         []int // Indexes of the Items matching the search
+	previewOffset int   // First preview line shown
+	width, height int
+}
+
+// NewBrowser returns a browser listing items, newest first.
+func NewBrowser(items []BrowseItem) Browser {
+	for i := range items {
+		var words []string
+		for _, s := range append(index.Text(items[i].Entry), items[i].Entry.Template, items[i].Date.Format("2006-01-02 Monday January")) {
+			words = append(words, index.Words(s)...)
+		}
+		slices.Sort(words)
+		items[i].words = slices.Compact(words)
+	}
+	si := textinput.New()
+	si.Prompt = "/ "
+	si.Placeholder = "Search entries..."
+	b := Browser{Items: items, SearchInput: si, width: 100, height: 24}
+	b.filter()
+	return b
+}
+
+// Selected returns the entry under the cursor.
+func (b Browser) Selected() (BrowseItem, bool) {
+	if b.Cursor < 0 || b.Cursor >= len(b.shown) {
+		return BrowseItem{}, false
+	}
+	return b.Items[b.shown[b.Cursor]], true
+}
+
+// Select moves the cursor to the entry at path, if it is shown.
+func (b *Browser) Select(path string) {
+	for i, j := range b.shown {
+		if b.Items[j].Path == path {
+			b.Cursor = i
+			return
+		}
+	}
+}
+
+// Search narrows the list to the entries matching query.
+func (b *Browser) Search(query string) {
+	b.SearchInput.SetValue(query)
+	b.filter()
+}
+
+// filter lists the items matching the search and keeps the cursor on the
+// selected entry when it still matches.
+func (b *Browser) filter() {
+	selected, hadSelection := b.Selected()
+	query := index.Words(b.SearchInput.Value())
+	b.shown = nil
+	for i, it := range b.Items {
+		if fuzzyMatch(query, it.words) {
+			b.shown = append(b.shown, i)
+		}
+	}
+	b.Cursor = 0
+	if hadSelection {
+		b.Select(selected.Path)
+	}
+	b.previewOffset = 0
+}
+
+// fuzzyMatch reports whether every query word matches a word of the
+// entry: as part of it, or with its letters in order, e.g. "mgrtn" for
+// "migration".
+func fuzzyMatch(query, words []string) bool {
+	for _, q := range query {
+		if !slices.ContainsFunc(words, func(w string) bool {
+			return strings.Contains(w, q) || subsequence(q, w)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// subsequence reports whether the letters of q appear in w in order,
+// starting with its first letter.
+func subsequence(q, w string) bool {
+	qr, wr := []rune(q), []rune(w)
+	if len(qr) == 0 || len(wr) == 0 || qr[0] != wr[0] {
+		return false
+	}
+	i := 0
+	for _, r := range wr {
+		if i < len(qr) && r == qr[i] {
+			i++
+		}
+	}
+	return i == len(qr)
+}
+
+func (b Browser) Init() tea.Cmd {
+	return nil
+}
+
+func (b Browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+		return b, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return b.leave(BrowseQuit)
+		}
+		if b.Searching {
+			return b.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "esc":
+			if b.SearchInput.Value() != "" {
+				b.Search("")
+				return b, nil
+			}
+			return b.leave(BrowseQuit)
+		case "q":
+			return b.leave(BrowseQuit)
+		case "/":
+			b.Searching = true
+			return b, b.SearchInput.Focus()
+		case "enter", "e":
+			if _, ok := b.Selected(); ok {
+				return b.leave(BrowseEdit)
+			}
+		case "t":
+			if _, ok := b.Selected(); ok {
+				return b.leave(BrowseTodos)
+			}
+		case "ctrl+d":
+			b.previewOffset = min(b.previewOffset+b.listHeight()/2, max(0, len(b.previewLines())-b.listHeight()))
+		case "ctrl+u":
+			b.previewOffset = max(0, b.previewOffset-b.listHeight()/2)
+		default:
+			b.move(msg.String())
+		}
+	}
+	return b, nil
+}
+
+// updateSearch handles a key typed in the search input: the list follows
+// the query, enter keeps it and esc drops it.
+func (b Browser) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		b.Searching = false
+		b.SearchInput.Blur()
+		return b, nil
+	case tea.KeyEsc:
+		b.Searching = false
+		b.SearchInput.Blur()
+		b.Search("")
+		return b, nil
+	case tea.KeyUp, tea.KeyDown, tea.KeyPgUp, tea.KeyPgDown:
+		b.move(msg.String())
+		return b, nil
+	}
+	var cmd tea.Cmd
+	b.SearchInput, cmd = b.SearchInput.Update(msg)
+	b.filter()
+	return b, cmd
+}
+
+// move moves the cursor for a navigation key.
+func (b *Browser) move(key string) {
+	cursor := b.Cursor
+	switch key {
+	case "up", "k":
+		cursor--
+	case "down", "j":
+		cursor++
+	case "pgup":
+		cursor -= b.listHeight()
+	case "pgdown":
+		cursor += b.listHeight()
+	case "home", "g":
+		cursor = 0
+	case "end", "G":
+		cursor = len(b.shown) - 1
+	default:
+		return
+	}
+	b.Cursor = max(0, min(cursor, len(b.shown)-1))
+	b.previewOffset = 0
+}
+
+func (b Browser) leave(action BrowseAction) (tea.Model, tea.Cmd) {
+	b.Action, b.Done = action, true
+	return b, tea.Quit
+}
+
+// listHeight is the number of lines available to the list and preview.
+func (b Browser) listHeight() int {
+	return max(3, b.height-4) // Title, search and help lines
+}
+
+func (b Browser) View() string {
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Browse Journal"))
+	s.WriteString(subtle.Render(fmt.Sprintf("  %d of %d entries", len(b.shown), len(b.Items))))
+	s.WriteString("\n")
+	if b.Searching || b.SearchInput.Value() != "" {
+		s.WriteString(b.SearchInput.View())
+	}
+	s.WriteString("\n")
+
+	list := lipgloss.NewStyle().Width(listWidth).Render(strings.Join(b.listLines(), "\n"))
+	preview := "No matching entries."
+	if lines := b.previewLines(); len(lines) > 0 {
+		offset := min(b.previewOffset, len(lines)-1)
+		preview = strings.Join(lines[offset:min(len(lines), offset+b.listHeight())], "\n")
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list, "   ", preview))
+	s.WriteString("\n")
+	if b.Searching {
+		s.WriteString(subtle.Render("Type to search · ↑/↓ move · Enter keep · Esc clear"))
+	} else {
+		s.WriteString(subtle.Render("↑/↓ move · / search · Enter edit · t todos · ctrl+d/u scroll · q quit"))
+	}
+	return s.String()
+}
+
+// listWidth is the width of the entry list; the preview takes the rest.
+const listWidth = 24
+
+// previewLines renders the selected entry to fit next to the list.
+func (b Browser) previewLines() []string {
+	it, ok := b.Selected()
+	if !ok {
+		return nil
+	}
+	width := max(20, b.width-listWidth-3)
+	return strings.Split(lipgloss.NewStyle().Width(width).Render(renderEntry(it.Entry)), "\n")
+}
+
+// listLines renders the shown entries under month headings, scrolled so
+// the cursor is in view.
+func (b Browser) listLines() []string {
+	var lines []string
+	cursorLine := 0
+	var month time.Month
+	year := -1
+	for i, j := range b.shown {
+		it := b.Items[j]
+		if it.Date.Month() != month || it.Date.Year() != year {
+			month, year = it.Date.Month(), it.Date.Year()
+			lines = append(lines, stepStyle.Render(it.Date.Format("January 2006")))
+		}
+		line := fmt.Sprintf("  %s  %s", it.Date.Format("Mon 02"), it.Entry.Mood)
+		if i == b.Cursor {
+			cursorLine = len(lines)
+			lines = append(lines, selectedItemStyle.Render(">"+line[1:]))
+		} else {
+			lines = append(lines, itemStyle.Render(line))
+		}
+	}
+	h := b.listHeight()
+	if len(lines) <= h {
+		return lines
+	}
+	start := max(0, min(cursorLine-h/2, len(lines)-h))
+	return lines[start : start+h]
+}
+
+// renderEntry renders e for the preview.
+func renderEntry(e *domain.JournalEntry) string {
+	var s strings.Builder
+	s.WriteString(stepStyle.Render(e.Date.Format("Monday, 02 Jan 2006")))
+	if e.Template != "" {
+		s.WriteString(subtle.Render("  " + e.Template))
+	}
+	s.WriteString("\n\n")
+	var meta []string
+	if e.Mood != "" {
+		meta = append(meta, "Mood: "+e.Mood)
+	}
+	if e.Energy != "" {
+		meta = append(meta, "Energy: "+e.Energy)
+	}
+	if len(meta) > 0 {
+		s.WriteString(strings.Join(meta, " · ") + "\n")
+	}
+	if e.Highlight != "" {
+		s.WriteString("⭐ " + e.Highlight + "\n")
+	}
+	for _, section := range []struct {
+		title string
+		todos []domain.Todo
+	}{{"Todos", e.Todos}, {"Backlog", e.Backlog}} {
+		if len(section.todos) == 0 {
+			continue
+		}
+		s.WriteString("\n" + stepStyle.Render(section.title) + "\n")
+		for _, t := range section.todos {
+			s.WriteString(fmt.Sprintf("[%c] %s%s\n", t.Status.Mark(), t.Text, todoMeta(t, e.Date)))
+		}
+	}
+	for _, a := range e.Answers {
+		if strings.TrimSpace(a.Text) == "" {
+			continue
+		}
+		s.WriteString("\n" + stepStyle.Render(a.Question) + "\n")
+		s.WriteString(a.Text + "\n")
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
@@ -0,0 +1,379 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/stats"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CalendarColor is what the calendar colours the days with an entry by.
+type CalendarColor int
+
+const (
+	ColorEntries CalendarColor = iota // One colour for every entry
+	ColorMood
+	ColorEnergy
+	ColorLength // Words written in answers
+)
+
+var calendarColorNames = []string{"entries", "mood", "energy", "length"}
+
+func (c CalendarColor) String() string {
+	return calendarColorNames[c]
+}
+
+// ParseCalendarColor parses the name of a CalendarColor.
+func ParseCalendarColor(s string) (CalendarColor, error) {
+	if i := slices.Index(calendarColorNames, strings.ToLower(s)); i >= 0 {
+		return CalendarColor(i), nil
+	}
+	return 0, fmt.Errorf("unknown colouring %q (use %s)", s, strings.Join(calendarColorNames, ", "))
+}
+
+var (
+	// heat shades days from less to more, like a contribution graph.
+	heat = []lipgloss.Color{"#0e4429", "#006d32", "#26a641", "#39d353"}
+	// categories colour the most common moods or energies.
+	categories = []lipgloss.Color{"#7D56F4", "205", "#1F9CF0", "#FFB000", "#E05A47", "#26a641"}
+
+	noEntry = lipgloss.Color("238")
+	noValue = lipgloss.Color("244") // An entry without the value coloured by
+)
+
+// Calendar shows which days have an entry, as a month or a year grid,
+// with a day selected by the arrow keys.
+type Calendar struct {
+	Days    stats.Calendar
+	Cursor  time.Time // Selected day
+	Today   time.Time // Days after it cannot be selected
+	Year    bool      // Show the cursor's year instead of its month
+	ColorBy CalendarColor
+	Done    bool
+}
+
+// NewCalendar returns a calendar of days on the month of today.
+func NewCalendar(days stats.Calendar, today time.Time) Calendar {
+	today = dateexpr.Day(today)
+	return Calendar{Days: days, Cursor: today, Today: today}
+}
+
+func (c Calendar) Init() tea.Cmd {
+	return nil
+}
+
+func (c Calendar) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "q", "esc":
+		c.Done = true
+		return c, tea.Quit
+	case "v":
+		c.Year = !c.Year
+	case "c":
+		c.ColorBy = (c.ColorBy + 1) % CalendarColor(len(calendarColorNames))
+	case "t":
+		c.Cursor = c.Today
+	default:
+		c.Move(key.String())
+	}
+	return c, nil
+}
+
+// Move moves the cursor for a navigation key: arrows or h/j/k/l by a day
+// or a week, following the grid, and [ and ] by a month or a year.
+func (c *Calendar) Move(key string) {
+	// In the year grid weeks are columns; in the month grid, rows.
+	across, down := 1, 7
+	if c.Year {
+		across, down = 7, 1
+	}
+	cursor := c.Cursor
+	switch key {
+	case "left", "h":
+		cursor = cursor.AddDate(0, 0, -across)
+	case "right", "l":
+		cursor = cursor.AddDate(0, 0, across)
+	case "up", "k":
+		cursor = cursor.AddDate(0, 0, -down)
+	case "down", "j":
+		cursor = cursor.AddDate(0, 0, down)
+	case "[", "pgup":
+		cursor = c.page(-1)
+	case "]", "pgdown":
+		cursor = c.page(1)
+	default:
+		return
+	}
+	if cursor.After(c.Today) {
+		cursor = c.Today
+	}
+	c.Cursor = cursor
+}
+
+// page returns the cursor moved by n months, or years in the year grid,
+// keeping its day where the month has it.
+func (c Calendar) page(n int) time.Time {
+	y, m, d := c.Cursor.Date()
+	if c.Year {
+		y += n
+	} else {
+		m += time.Month(n)
+	}
+	first := time.Date(y, m, 1, 0, 0, 0, 0, c.Cursor.Location())
+	return first.AddDate(0, 0, min(d, daysIn(first))-1)
+}
+
+func daysIn(month time.Time) int {
+	return month.AddDate(0, 1, -month.Day()).Day()
+}
+
+// weekday numbers days from Monday (0) to Sunday (6).
+func weekday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func (c Calendar) View() string {
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("Journal Calendar"))
+	s.WriteString("\n\n")
+	if c.Year {
+		s.WriteString(c.YearGrid())
+	} else {
+		s.WriteString(c.MonthGrid())
+	}
+	s.WriteString("\n\n")
+	s.WriteString(c.dayDetails())
+	s.WriteString("\n")
+	_, legend := c.scale()
+	s.WriteString(legend)
+	s.WriteString("\n\n")
+	page, other := "month", "year"
+	if c.Year {
+		page, other = "year", "month"
+	}
+	s.WriteString(subtle.Render(fmt.Sprintf("←/→/↑/↓ move · [/] %s · v %s view · c colour by (%s) · t today · q quit",
+		page, other, c.ColorBy)))
+	return s.String()
+}
+
+// MonthGrid renders the month of the cursor, a week per row.
+func (c Calendar) MonthGrid() string {
+	color, _ := c.scale()
+	first := c.Cursor.AddDate(0, 0, 1-c.Cursor.Day())
+	n := daysIn(first)
+
+	var s strings.Builder
+	s.WriteString(stepStyle.Render(first.Format("January 2006")))
+	s.WriteString(subtle.Render(fmt.Sprintf("  %d of %d days", c.count(first, first.AddDate(0, 1, 0)), n)))
+	s.WriteString("\n" + subtle.Render(" Mo Tu We Th Fr Sa Su") + "\n")
+	s.WriteString(strings.Repeat("   ", weekday(first)))
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		cell := c.cell(day, fmt.Sprintf("%2d", day.Day()), color)
+		s.WriteString(" " + cell)
+		if weekday(day) == 6 && day.Day() < n {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+// YearGrid renders the year of the cursor like a contribution graph: a
+// column per week and a row per weekday.
+func (c Calendar) YearGrid() string {
+	color, _ := c.scale()
+	jan1 := time.Date(c.Cursor.Year(), 1, 1, 0, 0, 0, 0, c.Cursor.Location())
+	start := jan1.AddDate(0, 0, -weekday(jan1)) // Monday of the first week
+	next := jan1.AddDate(1, 0, 0)
+	weeks := (int(next.Sub(start).Hours()/24+0.5) + 6) / 7
+
+	// Month names over the week each month starts in.
+	labels := []rune(strings.Repeat(" ", weeks+3))
+	for m := 0; m < 12; m++ {
+		first := jan1.AddDate(0, m, 0)
+		col := int(first.Sub(start).Hours()/24+0.5) / 7
+		copy(labels[col:], []rune(first.Format("Jan")))
+	}
+
+	var s strings.Builder
+	s.WriteString(stepStyle.Render(jan1.Format("2006")))
+	s.WriteString(subtle.Render(fmt.Sprintf("  %d of %d days", c.count(jan1, next), next.AddDate(0, 0, -1).YearDay())))
+	s.WriteString("\n    " + subtle.Render(strings.TrimRight(string(labels), " ")) + "\n")
+	for row := 0; row < 7; row++ {
+		label := "   "
+		if row%2 == 0 && row < 6 {
+			label = start.AddDate(0, 0, row).Format("Mon")
+		}
+		s.WriteString(subtle.Render(label) + " ")
+		for col := 0; col < weeks; col++ {
+			day := start.AddDate(0, 0, col*7+row)
+			if day.Year() != jan1.Year() {
+				s.WriteString(" ")
+				continue
+			}
+			s.WriteString(c.cell(day, "■", color))
+		}
+		if row < 6 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+// cell renders the text of a day in its colour.
+func (c Calendar) cell(day time.Time, text string, color func(stats.Day) lipgloss.Color) string {
+	if day.Equal(c.Cursor) {
+		return selectedItemStyle.Reverse(true).Render(text)
+	}
+	if day.After(c.Today) {
+		return strings.Repeat(" ", len([]rune(text)))
+	}
+	d, ok := c.Days.Day(day)
+	if !ok {
+		return lipgloss.NewStyle().Foreground(noEntry).Render(text)
+	}
+	return lipgloss.NewStyle().Foreground(color(d)).Bold(true).Render(text)
+}
+
+// count returns the number of days from start up to end with an entry.
+func (c Calendar) count(start, end time.Time) int {
+	n := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if _, ok := c.Days.Day(day); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// dayDetails describes the entry of the cursor's day.
+func (c Calendar) dayDetails() string {
+	label := c.Cursor.Format("Monday, 02 Jan 2006")
+	d, ok := c.Days.Day(c.Cursor)
+	if !ok {
+		return label + subtle.Render(" · no entry")
+	}
+	parts := []string{label}
+	if d.Mood != "" {
+		parts = append(parts, "mood "+d.Mood)
+	}
+	if d.Energy != "" {
+		parts = append(parts, "energy "+d.Energy)
+	}
+	parts = append(parts, fmt.Sprintf("%d words", d.Words))
+	return strings.Join(parts, " · ")
+}
+
+// scale returns the colour of a day with an entry for ColorBy, and a
+// legend describing the colours. Colours are chosen over all the days so
+// they do not change when paging.
+func (c Calendar) scale() (func(stats.Day) lipgloss.Color, string) {
+	swatch := func(color lipgloss.Color) string {
+		return lipgloss.NewStyle().Foreground(color).Render("■")
+	}
+	gradient := subtle.Render("less ")
+	for _, color := range heat {
+		gradient += swatch(color)
+	}
+	gradient += subtle.Render(" more")
+
+	switch c.ColorBy {
+	case ColorLength:
+		most := 0
+		for _, d := range c.Days {
+			most = max(most, d.Words)
+		}
+		return func(d stats.Day) lipgloss.Color {
+			if d.Words == 0 {
+				return noValue
+			}
+			return heat[int(math.Ceil(4*float64(d.Words)/float64(most)))-1]
+		}, gradient + subtle.Render(fmt.Sprintf(" (up to %d words)", most))
+	case ColorMood, ColorEnergy:
+		return c.valueScale(swatch, gradient)
+	}
+	return func(stats.Day) lipgloss.Color { return heat[2] },
+		swatch(heat[2]) + subtle.Render(" entry  ") + swatch(noEntry) + subtle.Render(" none")
+}
+
+// valueScale colours days by mood or energy: on a gradient when every
+// value is a number, such as a 1-5 rating, otherwise a colour for each of
+// the most common values.
+func (c Calendar) valueScale(swatch func(lipgloss.Color) string, gradient string) (func(stats.Day) lipgloss.Color, string) {
+	value := func(d stats.Day) string {
+		if c.ColorBy == ColorEnergy {
+			return strings.ToLower(strings.TrimSpace(d.Energy))
+		}
+		return strings.ToLower(strings.TrimSpace(d.Mood))
+	}
+
+	counts := map[string]int{}
+	numeric := true
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, d := range c.Days {
+		v := value(d)
+		if v == "" {
+			continue
+		}
+		counts[v]++
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			low, high = math.Min(low, n), math.Max(high, n)
+		} else {
+			numeric = false
+		}
+	}
+
+	if numeric && len(counts) > 0 {
+		return func(d stats.Day) lipgloss.Color {
+			n, err := strconv.ParseFloat(value(d), 64)
+			if err != nil {
+				return noValue
+			}
+			if high == low {
+				return heat[len(heat)-1]
+			}
+			return heat[int((n-low)/(high-low)*float64(len(heat)-1)+0.5)]
+		}, gradient + subtle.Render(fmt.Sprintf(" (%s %g to %g)", c.ColorBy, low, high))
+	}
+
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	slices.SortFunc(values, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	colors := map[string]lipgloss.Color{}
+	var legend []string
+	for i, v := range values {
+		if i == len(categories) {
+			legend = append(legend, swatch(noValue)+subtle.Render(" other"))
+			break
+		}
+		colors[v] = categories[i]
+		legend = append(legend, swatch(categories[i])+" "+v)
+	}
+	if len(legend) == 0 {
+		legend = append(legend, subtle.Render(fmt.Sprintf("No %s recorded.", c.ColorBy)))
+	}
+	return func(d stats.Day) lipgloss.Color {
+		if color, ok := colors[value(d)]; ok {
+			return color
+		}
+		return noValue
+	}, strings.Join(legend, "  ")
+}
@@ -19,16 +19,41 @@ const (
 	StepEnergy
 	StepHighlight
 	StepTodos
+	StepSummary // Reviews: the overview of the period's daily entries
 	StepQuestions
 	StepDone
 )
 
+// Placeholders of the question inputs, used unless a question sets its own.
+const (
+	answerPlaceholder = "Write your answer..."
+	numberPlaceholder = "Enter a number..."
+	listPlaceholder   = "Add an item..."
+)
+
 type Model struct {
 	Config    *config.Config
 	Templates []template.Template
 	Entry     *domain.JournalEntry
 	Stats     stats.Stats
 
+	// Backdated is set when the entry is for a past date rather than today.
+	Backdated bool
+
+	// TemplateWarnings lists problems found while loading templates; they
+	// are shown under the template list.
+	TemplateWarnings []string
+
+	// SuggestedTemplate is the template the config's template rules pick
+	// for the entry date; it is marked in the template list.
+	SuggestedTemplate string
+
+	// Summary is the overview shown before the questions of a review.
+	Summary string
+
+	// Calendar is shown next to the template list when it has days.
+	Calendar Calendar
+
 	CurrentStep    Step
 	TemplateCursor int
 	QuestionIndex  int
@@ -38,10 +63,30 @@ type Model struct {
 	EnergyInput    textinput.Model
 	HighlightInput textinput.Model
 
+	// Widgets for typed questions (see question.go). QuestionInput is used
+	// for text questions.
+	ScaleValue     int
+	ScaleSet       bool
+	ChoiceCursor   int // -1 until a choice is made
+	ChoiceSelected map[int]bool
+	NumberInput    textinput.Model
+	ListInput      textinput.Model
+	ListItems      []string
+	QuestionErr    string // Validation message for the current answer
+
 	// For Todos
 	BacklogCursor   int
 	SelectedBacklog map[int]bool // Index in Entry.Backlog -> true if selected
 	TodoMode        bool         // true if adding a todo, false if reviewing backlog
+	editTodo        domain.Todo  // Todo being edited in TodoInput, to keep its metadata
+
+	// Backlog order and filter, and the due date prompt of the d key; see
+	// todos.go.
+	BacklogSort   BacklogSort
+	BacklogFilter BacklogFilter
+	DueEditing    bool
+	DueInput      textinput.Model
+	DueErr        string
 
 	// Todos menu when opening an existing entry to avoid navigation deadlocks
 	TodosMenuActive bool
@@ -56,7 +101,7 @@ func NewModel(cfg *config.Config, templates []template.Template, entry *domain.J
 	ti.Focus()
 
 	ta := textarea.New()
-	ta.Placeholder = "Write your answer..."
+	ta.Placeholder = answerPlaceholder
 	ta.SetHeight(5)
 
 	mi := textinput.New()
@@ -71,6 +116,15 @@ func NewModel(cfg *config.Config, templates []template.Template, entry *domain.J
 	hi.Placeholder = "What is your main focus today?"
 	hi.Focus()
 
+	ni := textinput.New()
+	ni.Placeholder = numberPlaceholder
+
+	li := textinput.New()
+	li.Placeholder = listPlaceholder
+
+	di := textinput.New()
+	di.Placeholder = "+3d, next friday, 2026-01-05; empty to clear"
+
 	return Model{
 		Config:          cfg,
 		Templates:       templates,
@@ -82,10 +136,29 @@ func NewModel(cfg *config.Config, templates []template.Template, entry *domain.J
 		MoodInput:       mi,
 		EnergyInput:     ei,
 		HighlightInput:  hi,
+		NumberInput:     ni,
+		ListInput:       li,
+		DueInput:        di,
+		ChoiceCursor:    -1,
+		ChoiceSelected:  make(map[int]bool),
 		SelectedBacklog: make(map[int]bool),
 	}
 }
 
+// SelectTemplate picks template i for the entry and moves on to the mood
+// step, or the summary of a review. ctrl+t goes back to the template list
+// until the questions start.
+func (m *Model) SelectTemplate(i int) {
+	m.TemplateCursor = i
+	m.Entry.Template = m.Templates[i].Name
+	if m.Entry.Period != domain.PeriodDay {
+		m.CurrentStep = StepSummary
+		return
+	}
+	m.CurrentStep = StepMood
+	m.MoodInput.Focus()
+}
+
 func (m Model) Init() tea.Cmd {
 	return textinput.Blink
 }
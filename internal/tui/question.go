@@ -0,0 +1,397 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"journal-cli/internal/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// currentQuestion returns the question being answered.
+func (m Model) currentQuestion() template.Question {
+	return m.Templates[m.TemplateCursor].Questions[m.QuestionIndex]
+}
+
+// SetQuestion moves to question i of the selected template and loads its
+// saved answer, or the question's default, into the question's widget.
+func (m *Model) SetQuestion(i int) {
+	m.CurrentStep = StepQuestions
+	m.QuestionIndex = i
+	m.QuestionErr = ""
+
+	m.QuestionInput.Reset()
+	m.QuestionInput.Blur()
+	m.NumberInput.Reset()
+	m.NumberInput.Blur()
+	m.ListInput.Reset()
+	m.ListInput.Blur()
+	m.ScaleSet = false
+	m.ChoiceCursor = -1
+	m.ChoiceSelected = make(map[int]bool)
+	m.ListItems = nil
+
+	if i >= len(m.Templates[m.TemplateCursor].Questions) {
+		return
+	}
+	q := m.currentQuestion()
+	answer, ok := m.Entry.Answer(q.Key())
+	if !ok {
+		answer = q.Default
+	}
+	answer = strings.TrimSpace(answer)
+	m.QuestionInput.Placeholder = cmp.Or(q.Placeholder, answerPlaceholder)
+	m.NumberInput.Placeholder = cmp.Or(q.Placeholder, numberPlaceholder)
+	m.ListInput.Placeholder = cmp.Or(q.Placeholder, listPlaceholder)
+
+	switch q.Kind() {
+	case template.TypeScale:
+		m.ScaleValue, _ = q.ScaleRange()
+		if v, ok := template.ParseScale(answer); ok {
+			m.ScaleValue, m.ScaleSet = v, true
+		}
+	case template.TypeChoice, template.TypeBoolean:
+		m.ChoiceCursor = slices.Index(q.Choices(), answer)
+	case template.TypeMultiChoice:
+		m.ChoiceCursor = 0
+		for _, item := range template.ParseItems(answer) {
+			if j := slices.Index(q.Options, item); j >= 0 {
+				m.ChoiceSelected[j] = true
+			}
+		}
+	case template.TypeNumber:
+		if v, ok := q.ParseNumber(answer); ok {
+			answer = template.FormatNumber(v)
+		}
+		m.NumberInput.SetValue(answer)
+		m.NumberInput.Focus()
+	case template.TypeList:
+		m.ListItems = template.ParseItems(answer)
+		m.ListInput.Focus()
+	default:
+		m.QuestionInput.SetValue(answer)
+		m.QuestionInput.Focus()
+	}
+}
+
+// questionValue returns the answer currently entered in the widget of the
+// current question, formatted as it is written to the entry.
+func (m Model) questionValue() string {
+	q := m.currentQuestion()
+	switch q.Kind() {
+	case template.TypeScale:
+		if m.ScaleSet {
+			return q.FormatScale(m.ScaleValue)
+		}
+		return ""
+	case template.TypeChoice, template.TypeBoolean:
+		if m.ChoiceCursor >= 0 && m.ChoiceCursor < len(q.Choices()) {
+			return q.Choices()[m.ChoiceCursor]
+		}
+		return ""
+	case template.TypeMultiChoice:
+		var items []string
+		for i, o := range q.Options {
+			if m.ChoiceSelected[i] {
+				items = append(items, o)
+			}
+		}
+		return template.FormatItems(items)
+	case template.TypeNumber:
+		text := strings.TrimSpace(m.NumberInput.Value())
+		if v, ok := q.ParseNumber(text); ok {
+			return q.FormatNumberAnswer(v)
+		}
+		return text
+	case template.TypeList:
+		return template.FormatItems(append(slices.Clone(m.ListItems), m.ListInput.Value()))
+	}
+	// Enter reaches the textarea before it moves on, leaving a newline.
+	return strings.TrimRight(m.QuestionInput.Value(), " \n")
+}
+
+// saveQuestion stores the current answer in the entry. Invalid answers, and
+// blank answers to required questions when moving forward, are not stored;
+// QuestionErr explains why and the caller stays on the question.
+func (m *Model) saveQuestion(forward bool) bool {
+	q := m.currentQuestion()
+	value := m.questionValue()
+	if err := q.Validate(value); err != nil {
+		m.QuestionErr = err.Error()
+		return false
+	}
+	if forward && q.Required && strings.TrimSpace(value) == "" {
+		m.QuestionErr = "This question needs an answer"
+		return false
+	}
+	m.QuestionErr = ""
+	m.Entry.SetAnswer(q.Key(), q.Title, value)
+	return true
+}
+
+// visibleQuestions reports which questions of the selected template are
+// asked given the answers so far.
+func (m Model) visibleQuestions() []bool {
+	return m.Templates[m.TemplateCursor].VisibleFor(m.Entry)
+}
+
+// QuestionVisible reports whether question i of the selected template is
+// asked given the answers so far.
+func (m Model) QuestionVisible(i int) bool {
+	return m.visibleQuestions()[i]
+}
+
+// nextQuestion saves the current answer and moves on.
+func (m Model) nextQuestion() (tea.Model, tea.Cmd) {
+	if !m.saveQuestion(true) {
+		return m, nil
+	}
+	return m.advance(m.QuestionIndex)
+}
+
+// advance moves to the first question after index from that is asked,
+// finishing the flow when there is none.
+func (m Model) advance(from int) (tea.Model, tea.Cmd) {
+	visible := m.visibleQuestions()
+	for i := from + 1; i < len(visible); i++ {
+		if visible[i] {
+			m.SetQuestion(i)
+			return m, nil
+		}
+	}
+	return m.finish()
+}
+
+// finish ends the flow unless a required question that is asked is still
+// blank, in which case it moves there. Answers to questions that are not
+// asked are dropped from the entry.
+func (m Model) finish() (tea.Model, tea.Cmd) {
+	tmpl := m.Templates[m.TemplateCursor]
+	visible := m.visibleQuestions()
+	for i, q := range tmpl.Questions {
+		if !visible[i] {
+			m.Entry.RemoveAnswer(q.Key())
+			continue
+		}
+		if answer, _ := m.Entry.Answer(q.Key()); q.Required && strings.TrimSpace(answer) == "" {
+			m.SetQuestion(i)
+			m.QuestionErr = "This question needs an answer"
+			return m, nil
+		}
+	}
+	m.CurrentStep = StepDone
+	return m, tea.Quit
+}
+
+func (m Model) updateQuestions(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.QuestionIndex >= len(m.Templates[m.TemplateCursor].Questions) {
+		return m.finish()
+	}
+
+	var cmd tea.Cmd
+	q := m.currentQuestion()
+	if q.Kind() == template.TypeText {
+		m.QuestionInput, cmd = m.QuestionInput.Update(msg)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, cmd
+	}
+	switch {
+	case key.String() == "shift+left":
+		visible := m.visibleQuestions()
+		for i := m.QuestionIndex - 1; i >= 0; i-- {
+			if visible[i] {
+				if m.saveQuestion(false) {
+					m.SetQuestion(i)
+				}
+				break
+			}
+		}
+		return m, nil
+	case key.String() == "shift+right",
+		key.Type == tea.KeyShiftTab && !key.Alt,
+		key.Type == tea.KeyCtrlS || key.Type == tea.KeyCtrlN:
+		return m.nextQuestion()
+	}
+
+	switch q.Kind() {
+	case template.TypeScale:
+		lo, hi := q.ScaleRange()
+		switch key.String() {
+		case "left", "h", "down", "j":
+			if m.ScaleSet && m.ScaleValue > lo {
+				m.ScaleValue--
+			}
+			m.ScaleSet = true
+		case "right", "l", "up", "k":
+			if m.ScaleSet && m.ScaleValue < hi {
+				m.ScaleValue++
+			}
+			m.ScaleSet = true
+		case "backspace", "delete":
+			m.ScaleSet = false
+		case "enter":
+			return m.nextQuestion()
+		default:
+			// Digits set the value; a second digit extends it while it
+			// stays in range, so "1" "0" selects 10.
+			if len(key.Runes) == 1 && key.Runes[0] >= '0' && key.Runes[0] <= '9' {
+				d := int(key.Runes[0] - '0')
+				if m.ScaleSet && m.ScaleValue*10+d <= hi && m.ScaleValue > 0 {
+					d = m.ScaleValue*10 + d
+				}
+				if d >= lo && d <= hi {
+					m.ScaleValue, m.ScaleSet = d, true
+				}
+			}
+		}
+		m.QuestionErr = ""
+
+	case template.TypeChoice, template.TypeBoolean, template.TypeMultiChoice:
+		n := len(q.Choices())
+		switch key.String() {
+		case "up", "k":
+			if m.ChoiceCursor > 0 {
+				m.ChoiceCursor--
+			} else {
+				m.ChoiceCursor = n - 1
+			}
+		case "down", "j":
+			m.ChoiceCursor = (m.ChoiceCursor + 1) % n
+		case " ":
+			if q.Kind() == template.TypeMultiChoice && m.ChoiceCursor >= 0 {
+				m.ChoiceSelected[m.ChoiceCursor] = !m.ChoiceSelected[m.ChoiceCursor]
+			}
+		case "y":
+			if q.Kind() == template.TypeBoolean {
+				m.ChoiceCursor = 0
+			}
+		case "n":
+			if q.Kind() == template.TypeBoolean {
+				m.ChoiceCursor = 1
+			}
+		case "backspace", "delete":
+			if q.Kind() != template.TypeMultiChoice {
+				m.ChoiceCursor = -1
+			}
+		case "enter":
+			return m.nextQuestion()
+		}
+		m.QuestionErr = ""
+
+	case template.TypeNumber:
+		if key.Type == tea.KeyEnter {
+			return m.nextQuestion()
+		}
+		m.NumberInput, cmd = m.NumberInput.Update(msg)
+		m.QuestionErr = ""
+
+	case template.TypeList:
+		switch key.Type {
+		case tea.KeyEnter:
+			// Enter adds the typed item; on an empty input it moves on.
+			if item := strings.TrimSpace(m.ListInput.Value()); item != "" {
+				m.ListItems = append(m.ListItems, item)
+				m.ListInput.Reset()
+				return m, nil
+			}
+			return m.nextQuestion()
+		case tea.KeyBackspace:
+			if m.ListInput.Value() == "" && len(m.ListItems) > 0 {
+				m.ListItems = m.ListItems[:len(m.ListItems)-1]
+				return m, nil
+			}
+		}
+		m.ListInput, cmd = m.ListInput.Update(msg)
+
+	default:
+		if key.Type == tea.KeyEnter {
+			return m.nextQuestion()
+		}
+	}
+	return m, cmd
+}
+
+func (m Model) questionView() string {
+	var s strings.Builder
+	q := m.currentQuestion()
+	title := q.Title
+	if q.Required {
+		title += " *"
+	}
+	s.WriteString(titleStyle.Render(title))
+	s.WriteString("\n\n")
+
+	hint := "Enter to save+next"
+	switch q.Kind() {
+	case template.TypeScale:
+		lo, hi := q.ScaleRange()
+		for v := lo; v <= hi; v++ {
+			if m.ScaleSet && v == m.ScaleValue {
+				s.WriteString(selectedItemStyle.Render(fmt.Sprintf("[%d]", v)))
+			} else {
+				s.WriteString(itemStyle.Render(fmt.Sprintf(" %d ", v)))
+			}
+		}
+		s.WriteString("\n")
+		if m.ScaleSet && q.Labels[m.ScaleValue] != "" {
+			s.WriteString(subtle.Render(fmt.Sprintf("%d: %s", m.ScaleValue, q.Labels[m.ScaleValue])))
+		} else if q.Labels[lo] != "" || q.Labels[hi] != "" {
+			s.WriteString(subtle.Render(fmt.Sprintf("%d = %s · %d = %s", lo, q.Labels[lo], hi, q.Labels[hi])))
+		}
+		hint = "Left/Right or digits to rate, Backspace to clear, Enter to save+next"
+
+	case template.TypeChoice, template.TypeBoolean, template.TypeMultiChoice:
+		multi := q.Kind() == template.TypeMultiChoice
+		for i, o := range q.Choices() {
+			cursor, style := " ", itemStyle
+			if m.ChoiceCursor == i {
+				cursor, style = ">", selectedItemStyle
+			}
+			mark := "( )"
+			if multi && m.ChoiceSelected[i] {
+				mark = "[x]"
+			} else if multi {
+				mark = "[ ]"
+			} else if m.ChoiceCursor == i {
+				mark = "(•)"
+			}
+			s.WriteString(style.Render(fmt.Sprintf("%s %s %s", cursor, mark, o)) + "\n")
+		}
+		switch {
+		case multi:
+			hint = "Up/Down to move, Space to toggle, Enter to save+next"
+		case q.Kind() == template.TypeBoolean:
+			hint = "y/n or Up/Down to choose, Enter to save+next"
+		default:
+			hint = "Up/Down to choose, Enter to save+next"
+		}
+
+	case template.TypeNumber:
+		s.WriteString(m.NumberInput.View())
+		if q.Unit != "" {
+			s.WriteString(" " + q.Unit)
+		}
+
+	case template.TypeList:
+		for _, item := range m.ListItems {
+			s.WriteString("- " + item + "\n")
+		}
+		s.WriteString(m.ListInput.View())
+		hint = "Enter to add, Empty Enter to save+next, Backspace on empty input removes the last item"
+
+	default:
+		s.WriteString(m.QuestionInput.View())
+	}
+
+	if m.QuestionErr != "" {
+		s.WriteString("\n\n" + errorStyle.Render(m.QuestionErr))
+	}
+	s.WriteString(fmt.Sprintf("\n\n(%s; Shift+Right to next; Shift+Left to previous; Ctrl+S or Ctrl+N still advances)", hint))
+	return s.String()
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"journal-cli/internal/dateexpr"
+	"journal-cli/internal/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BacklogSort is the order the backlog is listed in on the todo step. It
+// does not change the order the entry is saved in.
+type BacklogSort int
+
+const (
+	SortFile     BacklogSort = iota // As in the entry
+	SortPriority                    // Highest priority first
+	SortDue                         // Earliest due date first, undated last
+)
+
+func (s BacklogSort) String() string {
+	return [...]string{"entry order", "priority", "due date"}[s]
+}
+
+// BacklogFilter selects the backlog items listed on the todo step. Hidden
+// items stay in the backlog.
+type BacklogFilter int
+
+const (
+	FilterAll      BacklogFilter = iota
+	FilterDue                    // Items with a due date
+	FilterPriority               // Items of medium priority or higher
+)
+
+func (f BacklogFilter) String() string {
+	return [...]string{"all", "with a due date", "medium priority or higher"}[f]
+}
+
+// priorityCycle is the order the p key steps through.
+var priorityCycle = []domain.Priority{
+	domain.PriorityNone, domain.PriorityHighest, domain.PriorityHigh,
+	domain.PriorityMedium, domain.PriorityLow, domain.PriorityLowest,
+}
+
+func nextPriority(p domain.Priority) domain.Priority {
+	i := slices.Index(priorityCycle, p)
+	return priorityCycle[(i+1)%len(priorityCycle)]
+}
+
+// backlogView returns the indices in Entry.Backlog of the items listed on
+// the todo step, in the order they are shown.
+func (m Model) backlogView() []int {
+	var view []int
+	for i, t := range m.Entry.Backlog {
+		switch m.BacklogFilter {
+		case FilterDue:
+			if t.Due.IsZero() {
+				continue
+			}
+		case FilterPriority:
+			if t.Priority < domain.PriorityMedium {
+				continue
+			}
+		}
+		view = append(view, i)
+	}
+	backlog := m.Entry.Backlog
+	switch m.BacklogSort {
+	case SortPriority:
+		slices.SortStableFunc(view, func(a, b int) int { return int(backlog[b].Priority - backlog[a].Priority) })
+	case SortDue:
+		slices.SortStableFunc(view, func(a, b int) int {
+			da, db := backlog[a].Due, backlog[b].Due
+			if da.IsZero() || db.IsZero() {
+				return boolCmp(da.IsZero(), db.IsZero())
+			}
+			return da.Compare(db)
+		})
+	}
+	return view
+}
+
+// boolCmp orders false before true.
+func boolCmp(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// cursorTodo returns the todo under the cursor of the todo list: a listed
+// backlog item or an added todo.
+func (m Model) cursorTodo() *domain.Todo {
+	view := m.backlogView()
+	if m.BacklogCursor < len(view) {
+		return &m.Entry.Backlog[view[m.BacklogCursor]]
+	}
+	if i := m.BacklogCursor - len(view); i >= 0 && i < len(m.Entry.Todos) {
+		return &m.Entry.Todos[i]
+	}
+	return nil
+}
+
+// updateTodoKeys handles the keys that set the metadata of the todo under
+// the cursor and arrange the backlog. It reports whether msg was used.
+func (m *Model) updateTodoKeys(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "p":
+		if t := m.cursorTodo(); t != nil {
+			t.Priority = nextPriority(t.Priority)
+		}
+	case "d":
+		if t := m.cursorTodo(); t != nil {
+			m.DueEditing = true
+			m.DueErr = ""
+			m.DueInput.Reset()
+			if !t.Due.IsZero() {
+				m.DueInput.SetValue(t.Due.Format("2006-01-02"))
+			}
+			m.DueInput.Focus()
+		}
+	case "s":
+		m.BacklogSort = (m.BacklogSort + 1) % 3
+	case "f":
+		m.BacklogFilter = (m.BacklogFilter + 1) % 3
+		m.BacklogCursor = 0
+	default:
+		return false
+	}
+	return true
+}
+
+// updateDue handles the due date prompt opened by the d key.
+func (m Model) updateDue(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.DueEditing = false
+			m.DueInput.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			t := m.cursorTodo()
+			if t == nil {
+				m.DueEditing = false
+				return m, nil
+			}
+			value := strings.TrimSpace(m.DueInput.Value())
+			if value == "" {
+				t.Due = time.Time{}
+			} else {
+				due, err := dateexpr.Parse(value, m.Entry.Date)
+				if err != nil {
+					m.DueErr = err.Error()
+					return m, nil
+				}
+				t.Due = due
+			}
+			m.DueEditing = false
+			m.DueInput.Blur()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.DueInput, cmd = m.DueInput.Update(msg)
+	return m, cmd
+}
+
+// todoMeta renders the status, priority and due date of t for the todo
+// list of an entry written on date.
+func todoMeta(t domain.Todo, date time.Time) string {
+	var parts []string
+	if t.Status != domain.StatusOpen {
+		parts = append(parts, "("+t.Status.String()+")")
+	}
+	if sym := t.Priority.Symbol(); sym != "" {
+		parts = append(parts, sym)
+	}
+	if !t.Due.IsZero() {
+		due := "📅 " + t.Due.Format("Mon 02 Jan")
+		if t.Overdue(date) {
+			due += " (overdue)"
+		}
+		parts = append(parts, due)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+// backlogHeader names the backlog's source, sort and filter.
+func (m Model) backlogHeader(shown int) string {
+	from := ""
+	if !m.Entry.BacklogFrom.IsZero() {
+		from = " from " + m.Entry.BacklogFrom.Format("Mon 02 Jan")
+	}
+	var opts []string
+	if m.BacklogSort != SortFile {
+		opts = append(opts, "by "+m.BacklogSort.String())
+	}
+	if m.BacklogFilter != FilterAll {
+		opts = append(opts, fmt.Sprintf("%s, %d of %d", m.BacklogFilter, shown, len(m.Entry.Backlog)))
+	}
+	if len(opts) > 0 {
+		from += " [" + strings.Join(opts, "; ") + "]"
+	}
+	return fmt.Sprintf("🔁 Backlog%s (Up/Down to select, Space to toggle):\n", from)
+}
@@ -14,6 +14,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.Type {
 		case tea.KeyCtrlC:
 			return m, tea.Quit
+		case tea.KeyCtrlT:
+			// Change the template, e.g. one picked by a config rule.
+			switch m.CurrentStep {
+			case StepMood, StepEnergy, StepHighlight, StepSummary:
+				m.CurrentStep = StepSelectTemplate
+				return m, nil
+			}
 		}
 	}
 
@@ -31,10 +38,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.TemplateCursor++
 				}
 			case "enter":
-				m.Entry.Template = m.Templates[m.TemplateCursor].Name
-				m.CurrentStep = StepMood
-				m.MoodInput.Focus()
+				m.SelectTemplate(m.TemplateCursor)
 				return m, nil
+			case "left", "right", "[", "]":
+				m.Calendar.Move(msg.String())
 			}
 		}
 
@@ -125,9 +132,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.DueEditing {
+			return m.updateDue(msg)
+		}
+
 		// We'll treat backlog items and added todos as a single linear selectable list.
-		// Backlog items come first, then Entry.Todos. The BacklogCursor indexes into that combined list.
-		totalSelectable := len(m.Entry.Backlog) + len(m.Entry.Todos)
+		// Listed backlog items come first (see backlogView), then Entry.Todos.
+		// The BacklogCursor indexes into that combined list.
+		view := m.backlogView()
+		totalSelectable := len(view) + len(m.Entry.Todos)
 
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
@@ -162,25 +175,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
-				// Space toggles backlog selection only (backlog indices are 0..len(backlog)-1)
+				// Space toggles backlog selection only (selection is keyed by index in Entry.Backlog)
 				if msg.String() == " " {
-					if m.BacklogCursor < len(m.Entry.Backlog) {
-						if m.SelectedBacklog[m.BacklogCursor] {
-							delete(m.SelectedBacklog, m.BacklogCursor)
+					if m.BacklogCursor < len(view) {
+						i := view[m.BacklogCursor]
+						if m.SelectedBacklog[i] {
+							delete(m.SelectedBacklog, i)
 						} else {
-							m.SelectedBacklog[m.BacklogCursor] = true
+							m.SelectedBacklog[i] = true
 						}
 					}
 					return m, nil
 				}
 
+				// p and d set the priority and due date of the todo under the cursor; s and f sort and filter the backlog
+				if m.updateTodoKeys(msg) {
+					return m, nil
+				}
+
 				// Enter on an added todo opens it for editing
 				if msg.String() == "enter" {
-					if m.BacklogCursor >= len(m.Entry.Backlog) {
-						idx := m.BacklogCursor - len(m.Entry.Backlog)
+					if m.BacklogCursor >= len(view) {
+						idx := m.BacklogCursor - len(view)
 						if idx >= 0 && idx < len(m.Entry.Todos) {
 							// Load the todo into input for editing, remove from list temporarily
-							val := m.Entry.Todos[idx].Text
+							m.editTodo = m.Entry.Todos[idx]
+							val := m.editTodo.Text
 							// remove from slice
 							m.Entry.Todos = append(m.Entry.Todos[:idx], m.Entry.Todos[idx+1:]...)
 							m.TodoInput.SetValue(val)
@@ -200,106 +220,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					val := m.TodoInput.Value()
 					if val == "" {
 						// Empty line means we are done with todos
-						m.CurrentStep = StepQuestions
-						m.QuestionIndex = 0
-						m.QuestionInput.Focus()
-						return m, nil
+						return m.advance(-1)
 					}
-					// Add todo (or re-add edited todo)
-					m.Entry.Todos = append(m.Entry.Todos, domain.Todo{Text: val, Done: false})
+					// Add todo (or re-add edited todo, keeping its ID and dates)
+					t := m.editTodo
+					t.Text = val
+					m.Entry.Todos = append(m.Entry.Todos, t)
+					m.editTodo = domain.Todo{}
 					m.TodoInput.Reset()
 				}
 			}
 			return m, cmd
 		}
 
-	case StepQuestions:
-		m.QuestionInput, cmd = m.QuestionInput.Update(msg)
-		switch msg := msg.(type) {
-		case tea.KeyMsg:
-			// Shift+Tab => Next question (save current)
-			if msg.Type == tea.KeyShiftTab && !msg.Alt {
-				currentTemplate := m.Templates[m.TemplateCursor]
-				question := currentTemplate.Questions[m.QuestionIndex].Title
-				m.Entry.Questions[question] = m.QuestionInput.Value()
-				m.QuestionInput.Reset()
-				m.QuestionIndex++
-				if m.QuestionIndex >= len(currentTemplate.Questions) {
-					m.CurrentStep = StepDone
-					return m, tea.Quit
-				}
-				nextQ := currentTemplate.Questions[m.QuestionIndex].Title
-				m.QuestionInput.SetValue(m.Entry.Questions[nextQ])
-				m.QuestionInput.Focus()
-				return m, nil
-			}
-
-			// Shift+Left => Previous question
-			if msg.String() == "shift+left" {
-				if m.QuestionIndex > 0 {
-					currentTemplate := m.Templates[m.TemplateCursor]
-					question := currentTemplate.Questions[m.QuestionIndex].Title
-					m.Entry.Questions[question] = m.QuestionInput.Value()
-					m.QuestionIndex--
-					prevQ := currentTemplate.Questions[m.QuestionIndex].Title
-					m.QuestionInput.SetValue(m.Entry.Questions[prevQ])
-					m.QuestionInput.Focus()
-				}
-				return m, nil
-			}
-
-			// Shift+Right => Next question
-			if msg.String() == "shift+right" {
-				currentTemplate := m.Templates[m.TemplateCursor]
-				question := currentTemplate.Questions[m.QuestionIndex].Title
-				m.Entry.Questions[question] = m.QuestionInput.Value()
-				m.QuestionInput.Reset()
-				m.QuestionIndex++
-				if m.QuestionIndex >= len(currentTemplate.Questions) {
-					m.CurrentStep = StepDone
-					return m, tea.Quit
-				}
-				nextQ := currentTemplate.Questions[m.QuestionIndex].Title
-				m.QuestionInput.SetValue(m.Entry.Questions[nextQ])
-				m.QuestionInput.Focus()
-				return m, nil
-			}
-
-			// Enter => save current and advance
-			if msg.Type == tea.KeyEnter {
-				currentTemplate := m.Templates[m.TemplateCursor]
-				question := currentTemplate.Questions[m.QuestionIndex].Title
-				m.Entry.Questions[question] = m.QuestionInput.Value()
-				m.QuestionInput.Reset()
-				m.QuestionIndex++
-				if m.QuestionIndex >= len(currentTemplate.Questions) {
-					m.CurrentStep = StepDone
-					return m, tea.Quit
-				}
-				nextQ := currentTemplate.Questions[m.QuestionIndex].Title
-				m.QuestionInput.SetValue(m.Entry.Questions[nextQ])
-				m.QuestionInput.Focus()
-				return m, nil
-			}
-
-			// Backwards compatible: Ctrl+S or Ctrl+N to submit answer
-			if msg.Type == tea.KeyCtrlS || msg.Type == tea.KeyCtrlN {
-				currentTemplate := m.Templates[m.TemplateCursor]
-				question := currentTemplate.Questions[m.QuestionIndex].Title
-				m.Entry.Questions[question] = m.QuestionInput.Value()
-				m.QuestionInput.Reset()
-				m.QuestionIndex++
-				if m.QuestionIndex >= len(currentTemplate.Questions) {
-					m.CurrentStep = StepDone
-					return m, tea.Quit
-				}
-				nextQ := currentTemplate.Questions[m.QuestionIndex].Title
-				m.QuestionInput.SetValue(m.Entry.Questions[nextQ])
-				m.QuestionInput.Focus()
-				return m, nil
-			}
+	case StepSummary:
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyEnter {
+			return m.advance(-1)
 		}
-		return m, cmd
+
+	case StepQuestions:
+		return m.updateQuestions(msg)
 	}
 
 	return m, nil
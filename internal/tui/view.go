@@ -3,6 +3,11 @@ package tui
 import (
 	"fmt"
 	"strings"
+
+	"journal-cli/internal/config"
+	"journal-cli/internal/domain"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 func (m Model) View() string {
@@ -12,6 +17,11 @@ func (m Model) View() string {
 		return errorStyle.Render(fmt.Sprintf("Error: %v", m.Err))
 	}
 
+	if m.Backdated && m.CurrentStep != StepDone {
+		s.WriteString(stepStyle.Render(fmt.Sprintf("📅 Writing the entry for %s", m.Entry.Date.Format("Monday, 02 Jan 2006"))))
+		s.WriteString("\n\n")
+	}
+
 	switch m.CurrentStep {
 	case StepSelectTemplate:
 		s.WriteString(titleStyle.Render("Select Template"))
@@ -19,11 +29,16 @@ func (m Model) View() string {
 
 		// Display Stats
 		s.WriteString(subtle.Render(fmt.Sprintf(" 📝 Total Entries: %d", m.Stats.TotalEntries)))
+		if m.Stats.LongestStreak > 0 {
+			s.WriteString(subtle.Render(fmt.Sprintf(" | 🔥 Streak: %d (best %d)", m.Stats.CurrentStreak, m.Stats.LongestStreak)))
+		}
 		if !m.Stats.LastMissed.IsZero() {
 			s.WriteString(subtle.Render(fmt.Sprintf(" | 🗓️  Last Missed: %s", m.Stats.LastMissed.Format("Monday, 02 Jan"))))
 		}
 		s.WriteString("\n\n")
 
+		// The templates are listed next to the calendar panel, if any.
+		var list strings.Builder
 		for i, t := range m.Templates {
 			cursor := " "
 			style := itemStyle
@@ -31,18 +46,36 @@ func (m Model) View() string {
 				cursor = ">"
 				style = selectedItemStyle
 			}
-			s.WriteString(style.Render(fmt.Sprintf("%s %s", cursor, t.Name)) + "\n")
+			name := t.Name
+			if t.Name == m.SuggestedTemplate {
+				name += " (suggested)"
+			}
+			list.WriteString(style.Render(fmt.Sprintf("%s %s", cursor, name)) + "\n")
 			if m.TemplateCursor == i && t.Description != "" {
-				s.WriteString(itemStyle.Render(fmt.Sprintf("    %s", t.Description)) + "\n")
+				list.WriteString(itemStyle.Render(fmt.Sprintf("    %s", t.Description)) + "\n")
+			}
+		}
+		if len(m.TemplateWarnings) > 0 {
+			list.WriteString("\n")
+			for _, w := range m.TemplateWarnings {
+				list.WriteString(errorStyle.Render("⚠ "+w) + "\n")
 			}
+			list.WriteString(subtle.Render("Run 'journal templates validate' for details.") + "\n")
+		}
+		if m.Calendar.Days != nil {
+			s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list.String(), "    ", m.Calendar.MonthGrid()))
+			s.WriteString("\n\n(Use arrow keys to select, Enter to confirm, [/] or ←/→ to browse the calendar)")
+		} else {
+			s.WriteString(list.String())
+			s.WriteString("\n(Use arrow keys to select, Enter to confirm)")
 		}
-		s.WriteString("\n(Use arrow keys to select, Enter to confirm)")
 
 	case StepMood:
 		s.WriteString(titleStyle.Render("How are you feeling?"))
 		s.WriteString("\n\n")
 		s.WriteString(m.MoodInput.View())
-		s.WriteString("\n\n(Enter to continue)")
+		s.WriteString(fmt.Sprintf("\n\n📋 Template: %s", m.Entry.Template))
+		s.WriteString("\n\n(Enter to continue, ctrl+t to change template)")
 
 	case StepEnergy:
 		s.WriteString(titleStyle.Render("How is your energy?"))
@@ -57,7 +90,11 @@ func (m Model) View() string {
 		s.WriteString("\n\n(Enter to continue)")
 
 	case StepTodos:
-		s.WriteString(titleStyle.Render("Today's Todos"))
+		if m.Backdated {
+			s.WriteString(titleStyle.Render("Todos – " + m.Entry.Date.Format("Mon, 02 Jan")))
+		} else {
+			s.WriteString(titleStyle.Render("Today's Todos"))
+		}
 		s.WriteString("\n\n")
 
 		// If Todos menu active, show options to avoid navigation deadlocks
@@ -78,47 +115,60 @@ func (m Model) View() string {
 		}
 
 		// Backlog + Added todos rendered as a single linear selectable list.
+		view := m.backlogView()
 		if len(m.Entry.Backlog) > 0 {
-			s.WriteString("🔁 Backlog (Up/Down to select, Space to toggle):\n")
-			for i, t := range m.Entry.Backlog {
+			s.WriteString(m.backlogHeader(len(view)))
+			for j, i := range view {
+				t := m.Entry.Backlog[i]
 				cursor := " "
-				if !m.TodoInput.Focused() && m.BacklogCursor == i {
+				if !m.TodoInput.Focused() && m.BacklogCursor == j {
 					cursor = ">"
 				}
 				checked := "[ ]"
 				if m.SelectedBacklog[i] {
 					checked = "[x]"
 				}
-				s.WriteString(fmt.Sprintf("%s %s %s\n", cursor, checked, t.Text))
+				s.WriteString(fmt.Sprintf("%s %s %s%s%s\n", cursor, checked, t.Text, todoMeta(t, m.Entry.Date), m.todoAge(t)))
 			}
 			s.WriteString("\n")
 		}
 
 		if len(m.Entry.Todos) > 0 {
 			s.WriteString("Added (Enter to edit):\n")
-			// offset index for added todos is len(Backlog)
-			off := len(m.Entry.Backlog)
+			// offset index for added todos is the number of listed backlog items
+			off := len(view)
 			for j, t := range m.Entry.Todos {
 				cursor := " "
 				if !m.TodoInput.Focused() && m.BacklogCursor == off+j {
 					cursor = ">"
 				}
-				s.WriteString(fmt.Sprintf("%s - %s\n", cursor, t.Text))
+				s.WriteString(fmt.Sprintf("%s - %s%s%s\n", cursor, t.Text, todoMeta(t, m.Entry.Date), m.todoAge(t)))
 			}
 			s.WriteString("\n")
 		}
 
+		if m.DueEditing {
+			s.WriteString("📅 Due date: " + m.DueInput.View())
+			if m.DueErr != "" {
+				s.WriteString("\n" + errorStyle.Render(m.DueErr))
+			}
+			s.WriteString("\n\n(Enter to set, Esc to cancel)")
+			break
+		}
 		s.WriteString(m.TodoInput.View())
 		s.WriteString("\n\n(Enter to add, Empty Enter to finish; Tab/Shift+Tab to switch focus; Up/Down to navigate; Enter on added todo to edit)")
+		s.WriteString("\n(In the list: p priority, d due date, s sort backlog, f filter backlog)")
+
+	case StepSummary:
+		s.WriteString(titleStyle.Render(m.Entry.Period.Title(m.Entry.Date)))
+		s.WriteString("\n\n")
+		s.WriteString(m.Summary)
+		s.WriteString("\n\n(Enter to continue to the questions, ctrl+t to change template)")
 
 	case StepQuestions:
 		currentTemplate := m.Templates[m.TemplateCursor]
 		if m.QuestionIndex < len(currentTemplate.Questions) {
-			q := currentTemplate.Questions[m.QuestionIndex].Title
-			s.WriteString(titleStyle.Render(q))
-			s.WriteString("\n\n")
-			s.WriteString(m.QuestionInput.View())
-			s.WriteString("\n\n(Enter to save+next; Shift+Right to next; Shift+Left to previous; Ctrl+S or Ctrl+N still advances)")
+			s.WriteString(m.questionView())
 		}
 
 	case StepDone:
@@ -128,3 +178,26 @@ func (m Model) View() string {
 
 	return s.String()
 }
+
+// todoAge describes how long a carried-over todo has been open, flagging
+// stale ones: " (carried 6 days)" or " ⚠ carried 9 days".
+func (m Model) todoAge(t domain.Todo) string {
+	if t.Carried == 0 {
+		return ""
+	}
+	age := fmt.Sprintf("carried %d times", t.Carried)
+	if days := t.Age(m.Entry.Date); days > 0 {
+		age = fmt.Sprintf("carried %d days", days)
+		if days == 1 {
+			age = "carried 1 day"
+		}
+	}
+	stale := config.DefaultStaleTodoDays
+	if m.Config != nil {
+		stale = m.Config.StaleTodoDays()
+	}
+	if t.Stale(m.Entry.Date, stale) {
+		return " ⚠ " + age
+	}
+	return " (" + age + ")"
+}